@@ -90,6 +90,7 @@ func TestDeepRespectsExplicitIncludeFalse(t *testing.T) {
 	assert.False(t, v.GetBool("include-events"), "explicit --include-events=false must survive --deep")
 	assert.False(t, v.GetBool("include-managed-fields"), "explicit --include-managed-fields=false must survive --deep")
 	assert.True(t, v.GetBool("include-owners"), "--deep must still enable flags the user didn't set explicitly")
+	assert.False(t, v.GetBool("include-workload-cluster"), "--deep must not connect to other clusters")
 }
 
 // TestE2ERegexFixturesAreAnchored guards the whole-output convention documented in
@@ -204,3 +205,254 @@ func TestAllArtifactsLocalShort(t *testing.T) {
 		})
 	}
 }
+
+// TestProblemsLocal covers --problems (pkg/plugin/problems.go): of the healthy Deployment, the
+// unavailable one and the two Ready pods, only the unavailable Deployment is rendered, under its
+// group-qualified kind heading; and a set with nothing problematic says so instead of failing
+// with "no resources found".
+func TestProblemsLocal(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	opts := combineOpts(testHackOpts(t), viperTestHackOpts())
+	tests := []cmdTest{
+		{
+			name: "only the problematic resources are rendered, grouped by kind",
+			args: []string{
+				"-f", "../tests/artifacts/deployment-healthy.yaml",
+				"-f", "../tests/artifacts/deployment-unavailable-replicas.yaml",
+				"-f", "../tests/artifacts/multiple-2-pods-docs.yaml",
+				"--local", "--problems", "--short",
+			},
+			stdoutEqualPath: "artifacts/problems-mixed.short.out",
+		},
+		{
+			name:            "nothing problematic",
+			args:            []string{"-f", "../tests/artifacts/deployment-healthy.yaml", "--local", "--problems"},
+			stdoutEqualPath: "artifacts/problems-none.out",
+		},
+		{
+			name:        "--problems and --watch are mutually exclusive",
+			args:        []string{"-f", "../tests/artifacts/deployment-healthy.yaml", "--local", "--problems", "--watch"},
+			stderrRegex: `--problems and --watch are mutually exclusive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, nil, opts...)
+		})
+	}
+}
+
+// TestExitCodeLocal covers --exit-code: the process exit code main() derives from Run's
+// *plugin.HealthExitError, with the worst kstatus among everything rendered winning.
+func TestExitCodeLocal(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	opts := combineOpts(testHackOpts(t), viperTestHackOpts())
+	tests := []struct {
+		name     string
+		files    []string
+		wantCode int
+	}{
+		{
+			name:     "healthy",
+			files:    []string{"deployment-healthy.yaml"},
+			wantCode: plugin.ExitCodeHealthy,
+		},
+		{
+			name:     "in progress",
+			files:    []string{"deployment-healthy.yaml", "deployment-unavailable-replicas.yaml"},
+			wantCode: plugin.ExitCodeInProgress,
+		},
+		{
+			name:     "failed outranks in progress",
+			files:    []string{"deployment-unavailable-replicas.yaml", "job-failed.yaml"},
+			wantCode: plugin.ExitCodeFailed,
+		},
+		{
+			name:     "synced and healthy Argo CD Application",
+			files:    []string{"argocd-application-healthy-multisource.yaml"},
+			wantCode: plugin.ExitCodeHealthy,
+		},
+		{
+			name:     "OutOfSync Argo CD Application",
+			files:    []string{"argocd-application-outofsync.yaml"},
+			wantCode: plugin.ExitCodeInProgress,
+		},
+		{
+			name:     "Degraded Argo CD Application",
+			files:    []string{"argocd-application-degraded.yaml"},
+			wantCode: plugin.ExitCodeFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"--local", "--short", "--exit-code"}
+			for _, file := range tt.files {
+				args = append(args, "-f", "../tests/artifacts/"+file)
+			}
+			_, stderr, err := executeCMD(t, args, opts...)
+			if tt.wantCode == plugin.ExitCodeHealthy {
+				require.NoError(t, err)
+				assert.Empty(t, stderr)
+				return
+			}
+			var healthErr *plugin.HealthExitError
+			require.ErrorAs(t, err, &healthErr)
+			assert.Equal(t, tt.wantCode, healthErr.Code)
+			assert.Contains(t, stderr, "resources are not Current")
+		})
+	}
+}
+
+func TestWaitForValidation(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	tests := []cmdTest{
+		{
+			name:        "only healthy is supported",
+			args:        []string{"deploy/x", "--wait-for=ready"},
+			stderrRegex: `--wait-for only supports 'healthy', got "ready"`,
+		},
+		{
+			name:        "--wait-for needs a cluster to watch",
+			args:        []string{"-f", "../tests/artifacts/deployment-healthy.yaml", "--local", "--wait-for=healthy"},
+			stderrRegex: `--wait-for and --local are mutually exclusive`,
+		},
+		{
+			name:        "--wait-for already watches",
+			args:        []string{"deploy/x", "--wait-for=healthy", "--watch"},
+			stderrRegex: `--wait-for and --watch are mutually exclusive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, nil)
+		})
+	}
+}
+
+func TestTUIValidation(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	tests := []cmdTest{
+		{
+			name:        "--tui already refreshes",
+			args:        []string{"deploy/x", "--tui", "--watch"},
+			stderrRegex: `--tui and --watch are mutually exclusive`,
+		},
+		{
+			name:        "--tui has no report to print",
+			args:        []string{"deploy/x", "--tui", "-o", "json"},
+			stderrRegex: `--tui and --output are mutually exclusive`,
+		},
+		{
+			name:        "--tui needs a terminal",
+			args:        []string{"-f", "../tests/artifacts/deployment-healthy.yaml", "--local", "--tui"},
+			stderrRegex: `--tui needs an interactive terminal`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, nil)
+		})
+	}
+}
+
+// TestIncludeWorkloadClusterValidation: connecting to a Cluster API workload cluster needs a live
+// management cluster, and a client neither --snapshot-out nor --snapshot-in can stand in for.
+func TestIncludeWorkloadClusterValidation(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	tests := []cmdTest{
+		{
+			name:        "no Secret to read the kubeconfig from",
+			args:        []string{"-f", "../tests/artifacts/clusterapi-machine-waiting-for-node.yaml", "--local", "--include-workload-cluster"},
+			stderrRegex: `--include-workload-cluster and --local are mutually exclusive`,
+		},
+		{
+			name:        "the workload cluster isn't recorded",
+			args:        []string{"machines", "--include-workload-cluster", "--snapshot-out", "a.tar.gz"},
+			stderrRegex: `--include-workload-cluster and --snapshot-out are mutually exclusive`,
+		},
+		{
+			name:        "a replay stays offline",
+			args:        []string{"--include-workload-cluster", "--snapshot-in", "a.tar.gz"},
+			stderrRegex: `--include-workload-cluster and --snapshot-in are mutually exclusive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, nil)
+		})
+	}
+}
+
+func TestClusterDashboardValidation(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	tests := []cmdTest{
+		{
+			name:        "the dashboard needs a cluster",
+			args:        []string{"cluster", "-f", "../tests/artifacts/deployment-healthy.yaml", "--local"},
+			stderrRegex: "`kubectl status cluster` and --local are mutually exclusive",
+		},
+		{
+			name:        "the dashboard has no report form",
+			args:        []string{"cluster", "-o", "json"},
+			stderrRegex: "`kubectl status cluster` and --output are mutually exclusive",
+		},
+		{
+			name:        "the dashboard has no watch form",
+			args:        []string{"cluster", "--watch"},
+			stderrRegex: "`kubectl status cluster` and --watch are mutually exclusive",
+		},
+		{
+			name:        "the dashboard has no short form",
+			args:        []string{"cluster", "--short"},
+			stderrRegex: "`kubectl status cluster` and --short are mutually exclusive",
+		},
+		{
+			name:        "the dashboard isn't diffed against a baseline",
+			args:        []string{"cluster", "--since-snapshot", "before.json"},
+			stderrRegex: "`kubectl status cluster` and --since-snapshot are mutually exclusive",
+		},
+		{
+			name:        "the dashboard isn't recorded",
+			args:        []string{"cluster", "--snapshot-out", "bundle.tar.gz"},
+			stderrRegex: "`kubectl status cluster` and --snapshot-out are mutually exclusive",
+		},
+		{
+			name:        "the dashboard isn't replayed",
+			args:        []string{"cluster", "--snapshot-in", "bundle.tar.gz"},
+			stderrRegex: "`kubectl status cluster` and --snapshot-in are mutually exclusive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, nil)
+		})
+	}
+}
+
+// TestOutputReportLocal covers -o json: one report per object, carrying kstatus' verdict and the
+// conditions the text view would flag, and no text view around it.
+func TestOutputReportLocal(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/dev/null")
+	opts := combineOpts(testHackOpts(t), viperTestHackOpts())
+	tests := []cmdTest{
+		{
+			name: "json report",
+			args: []string{
+				"-f", "../tests/artifacts/deployment-unavailable-replicas.yaml",
+				"-f", "../tests/artifacts/job-failed.yaml",
+				"--local", "-o", "json",
+			},
+			stdoutEqualPath: "artifacts/report-deployment-and-job.out",
+		},
+		{
+			name:        "unsupported format",
+			args:        []string{"-f", "../tests/artifacts/deployment-healthy.yaml", "--local", "-o", "wide"},
+			stderrRegex: `--output must be 'json' or 'yaml', got "wide"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, nil, opts...)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 
@@ -27,6 +28,10 @@ func main() {
 	// setting the TZ to UTC is safe.
 	_ = os.Setenv("TZ", "UTC")
 	if err := RootCmd().Execute(); err != nil {
+		var healthErr *plugin.HealthExitError
+		if errors.As(err, &healthErr) {
+			os.Exit(healthErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -39,7 +44,10 @@ Prints human-friendly output that focuses on the status of the resources in kube
 In most cases replacing a "kubectl get ..." with a "kubectl status ..." would be sufficient.
 
 This plugin uses templates for well known api-conventions and has support for hardcoded resources,
-not all resources are fully supported.`
+not all resources are fully supported.
+
+"kubectl status cluster" prints a cluster-wide health overview instead of any one resource; use
+"clusters" or "clusters.cluster.x-k8s.io" for Cluster API's Cluster resources.`
 
 	examplesMessage = `  # Show status of all pods in the current namespace
   kubectl status pods
@@ -70,6 +78,36 @@ not all resources are fully supported.`
 
   # Show status of nodes marked as master
   kubectl status node -l node-role.kubernetes.io/master
+
+  # Show only the resources that are not healthy, across every resource type in a namespace
+  kubectl status --problems -n payments
+
+  # Fail a CI job unless the deployment finished rolling out (exit code 2: in progress, 3: failed, 4: not found)
+  kubectl status deploy/my-dep --exit-code
+
+  # Wait up to 5 minutes for a deployment to become healthy, then show its status
+  kubectl status deploy/my-dep --wait-for=healthy --timeout=5m
+
+  # Print the health verdict, unhealthy conditions, warning events and owners as JSON
+  kubectl status deploy/my-dep -o json
+
+  # Record everything a render needs into a bundle, then re-render it later without the cluster
+  kubectl status deploy/my-dep --deep --snapshot-out status.tar.gz
+  kubectl status --snapshot-in status.tar.gz
+
+  # Show how a deployment's health changed since a recorded snapshot, or between two of them
+  kubectl status deploy/my-dep --since-snapshot before.tar.gz
+  kubectl status --snapshot-in after.tar.gz --since-snapshot before.tar.gz
+
+  # Keep re-rendering a deployment in full whenever it, its pods or their events change
+  kubectl status deploy/my-dep --watch --deep
+
+  # Cluster-wide overview: node readiness and pressure, unavailable APIServices, non-Current
+  # workloads per namespace, pending PVCs and webhooks whose service is down
+  kubectl status cluster
+
+  # Browse the pods' summaries interactively, opening any of them and following related objects
+  kubectl status pods --tui
 `
 )
 
@@ -112,15 +150,35 @@ func RootCmd(cfgOpts ...func(*plugin.RenderConfig)) *cobra.Command {
 		if err := checkErr(complete(f, v)); err != nil {
 			return err
 		}
-		if err := checkErr(validate(v)); err != nil {
+		if err := checkErr(validate(v, args)); err != nil {
+			return err
+		}
+		snapshot, args, err := startSnapshot(configFlags, v, cfg, args)
+		if err := checkErr(err); err != nil {
 			return err
 		}
+		defer snapshot.cleanup()
+		cleanupBaseline, args, err := startBaseline(v, configFlags, cfg, args)
+		if err := checkErr(err); err != nil {
+			return err
+		}
+		defer cleanupBaseline()
 		if b, _ := cmd.Flags().GetBool("test-hack"); b {
 			v.Set("test-hack", true)
 			plugin.ApplyTestHack(cfg)
 		}
 		ioStreams := genericiooptions.IOStreams{In: cmd.InOrStdin(), Out: cmd.OutOrStdout(), ErrOut: cmd.ErrOrStderr()}
-		return checkErr(plugin.Run(f, ioStreams, args, cfg))
+		err = plugin.Run(f, ioStreams, args, cfg)
+		if snapshotErr := snapshot.write(v, args); snapshotErr != nil && err == nil {
+			err = snapshotErr
+		}
+		// A health verdict under --exit-code isn't a failure to report through CheckErr, which
+		// would flatten it into a plain error and lose the exit code main() needs.
+		var healthErr *plugin.HealthExitError
+		if errors.As(err, &healthErr) {
+			return healthErr
+		}
+		return checkErr(err)
 	}
 	return cmd
 }
@@ -231,7 +289,7 @@ func addRenderFlags(flags *pflag.FlagSet) {
 	flags.Bool("include-application-details", true,
 		"This will include well known application metadata into the output.")
 	flags.Bool("include-rollout-diffs", false,
-		"Include unified diff between stored revisions of Deployment, DaemonSet and StatefulSets.")
+		"Include unified diff between stored revisions of Deployment, DaemonSet and StatefulSets, and between an Argo Rollout's stable and canary ReplicaSets.")
 	flags.Bool("include-all-volumes", false,
 		"Include config-only volumes (configMap/secret/projected/downwardAPI) and per-container volume mount lists.")
 	flags.Bool("include-managed-fields", true,
@@ -242,14 +300,34 @@ func addRenderFlags(flags *pflag.FlagSet) {
 		"Include Kubelet API healthz, configz and stats/summary in the output.")
 	flags.Bool("include-node-detailed-usage", true,
 		"Include details about Pods' resource usage on a node. Does lots of queries against API Server and causes dramatic slow down.")
+	flags.Bool("include-workload-cluster", false,
+		"Show a Cluster API Machine's Node by connecting to its workload cluster with the admin kubeconfig in the \"<cluster>-kubeconfig\" Secret. Not turned on by --deep.")
 	flags.Bool("shallow", false,
 		"Set all --include-* flags to false and let user selectively enable them.")
 	flags.Bool("deep", false,
-		"Set all --include-* flags to true and let user selectively disable them.")
+		"Set all --include-* flags (except --include-workload-cluster) to true and let user selectively disable them.")
 	flags.BoolP("watch", "w", false,
-		"After listing/getting the requested object, watch for changes.")
+		"After listing/getting the requested object, watch for changes. Renders in shallow mode unless --deep is also given.")
+	flags.Bool("tui", false,
+		"Browse the matching resources interactively: a list of one-line summaries, each opening into its full view, from which owners and referenced objects can be followed. Refreshes every few seconds.")
 	flags.Bool("short", false,
 		"Print only each matching resource's one-line health summary (its \"<Kind>.summary\" template) instead of the full view, one line per resource.")
+	flags.StringP("output", "o", "",
+		"Print a machine-readable health report instead of the text view. One of 'json' or 'yaml'.")
+	flags.String("wait-for", "",
+		"Wait until the matching resources reach the given state before rendering them. Only 'healthy' (kstatus Current) is supported; gives up early when one of them fails or is deleted.")
+	flags.Duration("timeout", 0,
+		"How long --wait-for waits before giving up, e.g. 5m. Zero means wait forever.")
+	flags.String("snapshot-out", "",
+		"Record every API response the render needs into this .tar.gz bundle, for replaying later with --snapshot-in. Secret values are left out; their keys are kept.")
+	flags.String("snapshot-in", "",
+		"Render from a bundle recorded with --snapshot-out instead of a cluster. Without arguments, replays the recorded command's arguments and namespace.")
+	flags.String("since-snapshot", "",
+		"Instead of rendering, show how each resource's health changed since this bundle was recorded with --snapshot-out: flipped conditions, new events, replica count changes, new container restarts. Combine with --snapshot-in to compare two bundles.")
+	flags.Bool("exit-code", false,
+		"Exit with a code reflecting the worst kstatus among the rendered resources: 0 all Current, 2 in progress, 3 failed, 4 not found. 1 still means the command itself failed.")
+	flags.Bool("problems", false,
+		"Render only resources whose kstatus is not Current, grouped by kind. Without a TYPE argument, checks every listable resource type in the namespace (or all namespaces with -A).")
 	flags.Bool("help-all", false,
 		"Show all available flags.")
 	flags.String("color", "auto",
@@ -309,9 +387,13 @@ func allowExplicitIncludesOnly(v *viper.Viper) {
 	}
 }
 
+// explicitOnlyIncludes are --include-* flags --deep leaves alone, because they reach beyond the
+// cluster kubectl is pointed at.
+var explicitOnlyIncludes = []string{"include-workload-cluster"}
+
 func enableAllIncludes(v *viper.Viper) {
 	for key, val := range v.AllSettings() {
-		if strings.HasPrefix(key, "include") {
+		if strings.HasPrefix(key, "include") && !slices.Contains(explicitOnlyIncludes, key) {
 			switch val.(type) {
 			case bool:
 				if !isBoolConfigExplicitlySetToFalse(v, key) {
@@ -322,7 +404,7 @@ func enableAllIncludes(v *viper.Viper) {
 	}
 }
 
-func validate(v *viper.Viper) error {
+func validate(v *viper.Viper, args []string) error {
 	klog.V(5).InfoS("Validating cli options...")
 	if v.GetBool("shallow") && v.GetBool("deep") {
 		return fmt.Errorf("--shallow and --deep are mutually exclusive")
@@ -330,5 +412,92 @@ func validate(v *viper.Viper) error {
 	if v.GetBool("local") && len(v.GetStringSlice("filename")) == 0 {
 		return fmt.Errorf("when using --local, --filename must be provided")
 	}
+	if v.GetBool("problems") && v.GetBool("watch") {
+		return fmt.Errorf("--problems and --watch are mutually exclusive")
+	}
+	if v.GetBool("exit-code") && v.GetBool("watch") {
+		return fmt.Errorf("--exit-code and --watch are mutually exclusive")
+	}
+	if v.GetString("snapshot-out") != "" || v.GetString("snapshot-in") != "" {
+		if v.GetString("snapshot-out") != "" && v.GetString("snapshot-in") != "" {
+			return fmt.Errorf("--snapshot-out and --snapshot-in are mutually exclusive")
+		}
+		for _, flag := range []string{"watch", "local"} {
+			if v.GetBool(flag) {
+				return fmt.Errorf("--snapshot-out/--snapshot-in and --%s are mutually exclusive", flag)
+			}
+		}
+		if v.GetString("wait-for") != "" {
+			return fmt.Errorf("--snapshot-out/--snapshot-in and --wait-for are mutually exclusive")
+		}
+	}
+	if v.GetBool("include-workload-cluster") {
+		if v.GetBool("local") {
+			return fmt.Errorf("--include-workload-cluster and --local are mutually exclusive")
+		}
+		// The workload cluster gets a client of its own, which a bundle can neither record nor
+		// stand in for.
+		for _, flag := range []string{"snapshot-out", "snapshot-in"} {
+			if v.GetString(flag) != "" {
+				return fmt.Errorf("--include-workload-cluster and --%s are mutually exclusive", flag)
+			}
+		}
+	}
+	if output := v.GetString("output"); output != "" {
+		if output != "json" && output != "yaml" {
+			return fmt.Errorf("--output must be 'json' or 'yaml', got %q", output)
+		}
+		if v.GetBool("watch") {
+			return fmt.Errorf("--output and --watch are mutually exclusive")
+		}
+	}
+	if v.GetBool("tui") {
+		for _, flag := range []string{"watch", "short", "problems", "exit-code"} {
+			if v.GetBool(flag) {
+				return fmt.Errorf("--tui and --%s are mutually exclusive", flag)
+			}
+		}
+		for _, flag := range []string{"output", "wait-for", "snapshot-out"} {
+			if v.GetString(flag) != "" {
+				return fmt.Errorf("--tui and --%s are mutually exclusive", flag)
+			}
+		}
+	}
+	if v.GetString("since-snapshot") != "" {
+		for _, flag := range []string{"watch", "tui", "short", "problems", "exit-code", "local"} {
+			if v.GetBool(flag) {
+				return fmt.Errorf("--since-snapshot and --%s are mutually exclusive", flag)
+			}
+		}
+		for _, flag := range []string{"output", "wait-for", "snapshot-out"} {
+			if v.GetString(flag) != "" {
+				return fmt.Errorf("--since-snapshot and --%s are mutually exclusive", flag)
+			}
+		}
+	}
+	// The dashboard is an overview of its own: it has no per-object render to shorten, report,
+	// wait on, diff or record.
+	if plugin.IsClusterDashboardQuery(args) {
+		for _, flag := range plugin.ClusterDashboardBoolConflicts {
+			if v.GetBool(flag) {
+				return fmt.Errorf("`kubectl status cluster` and --%s are mutually exclusive", flag)
+			}
+		}
+		for _, flag := range plugin.ClusterDashboardStringConflicts {
+			if v.GetString(flag) != "" {
+				return fmt.Errorf("`kubectl status cluster` and --%s are mutually exclusive", flag)
+			}
+		}
+	}
+	if waitFor := v.GetString("wait-for"); waitFor != "" {
+		if waitFor != "healthy" {
+			return fmt.Errorf("--wait-for only supports 'healthy', got %q", waitFor)
+		}
+		for _, flag := range []string{"watch", "problems", "local"} {
+			if v.GetBool(flag) {
+				return fmt.Errorf("--wait-for and --%s are mutually exclusive", flag)
+			}
+		}
+	}
 	return nil
 }
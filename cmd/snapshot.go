@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+	"github.com/bergerx/kubectl-status/pkg/plugin"
+)
+
+// snapshotSession is the --snapshot-out/--snapshot-in state of one invocation. The zero value
+// (neither flag set) is a no-op, so RunE can call its methods unconditionally.
+type snapshotSession struct {
+	recorder   *input.SnapshotRecorder
+	capturedAt time.Time
+	cacheDir   string
+}
+
+// startSnapshot installs the recorder or replayer as configFlags' transport wrapper. Both modes
+// also point the discovery cache at a throwaway directory: while recording, a warm
+// ~/.kube/cache would answer discovery without a request ever reaching the recorder, leaving the
+// bundle unusable on any machine with a cold one; while replaying, a cache left by a real cluster
+// would mix that cluster's API groups into the replay.
+//
+// A replay without arguments reuses the recorded command's arguments, namespace and selector, and
+// pins the render's notion of "now" to the capture time so every "... ago" reads as it did then.
+func startSnapshot(configFlags *genericclioptions.ConfigFlags, v *viper.Viper, cfg *plugin.RenderConfig, args []string) (*snapshotSession, []string, error) {
+	session := &snapshotSession{}
+	outPath, inPath := v.GetString("snapshot-out"), v.GetString("snapshot-in")
+	if outPath == "" && inPath == "" {
+		return session, args, nil
+	}
+	cacheDir, err := os.MkdirTemp("", "kubectl-status-snapshot-")
+	if err != nil {
+		return session, args, err
+	}
+	session.cacheDir = cacheDir
+	*configFlags.CacheDir = cacheDir
+	if outPath != "" {
+		session.recorder = input.NewSnapshotRecorder()
+		session.capturedAt = time.Now().UTC()
+		configFlags.WrapConfigFn = session.recorder.WrapConfig
+		return session, args, nil
+	}
+	replayer, err := input.ReadSnapshotFile(inPath)
+	if err != nil {
+		return session, args, err
+	}
+	configFlags.WrapConfigFn = replayer.WrapConfig
+	meta := replayer.Meta
+	args = recordedQuery(configFlags, v, meta, args)
+	if !meta.CapturedAt.IsZero() {
+		cfg.Now = func() time.Time { return meta.CapturedAt }
+	}
+	return session, args, nil
+}
+
+// recordedQuery returns args, or when there are none (and no -f either), the arguments meta
+// recorded -- also taking over its namespace and selector, unless given explicitly.
+func recordedQuery(configFlags *genericclioptions.ConfigFlags, v *viper.Viper, meta input.SnapshotMeta, args []string) []string {
+	if len(args) > 0 || len(v.GetStringSlice("filename")) > 0 {
+		return args
+	}
+	// complete() already resolved the namespace from the kubeconfig; only an explicit -n
+	// takes precedence over the recorded one.
+	if *configFlags.Namespace == "" {
+		v.Set("namespace", meta.Namespace)
+	}
+	if !v.GetBool("all-namespaces") {
+		v.Set("all-namespaces", meta.AllNamespaces)
+	}
+	if v.GetString("selector") == "" {
+		v.Set("selector", meta.Selector)
+	}
+	return meta.Args
+}
+
+// startBaseline loads the --since-snapshot bundle as cfg.Baseline. Without arguments, the
+// query is the one the bundle recorded, as for a --snapshot-in replay; with both flags, the
+// --snapshot-in bundle's query wins, having been applied first.
+func startBaseline(v *viper.Viper, configFlags *genericclioptions.ConfigFlags, cfg *plugin.RenderConfig, args []string) (cleanup func(), _ []string, err error) {
+	path := v.GetString("since-snapshot")
+	if path == "" {
+		return func() {}, args, nil
+	}
+	replayer, err := input.ReadSnapshotFile(path)
+	if err != nil {
+		return func() {}, args, err
+	}
+	cacheDir, err := os.MkdirTemp("", "kubectl-status-baseline-")
+	if err != nil {
+		return func() {}, args, err
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(cacheDir); err != nil {
+			klog.V(2).ErrorS(err, "failed to remove baseline discovery cache", "dir", cacheDir)
+		}
+	}
+	cfg.Baseline = replayer.NewFactory(cacheDir)
+	cfg.BaselineCapturedAt = replayer.Meta.CapturedAt
+	return cleanup, recordedQuery(configFlags, v, replayer.Meta, args), nil
+}
+
+// write saves the recorded bundle under --snapshot-out; it is a no-op otherwise.
+func (s *snapshotSession) write(v *viper.Viper, args []string) error {
+	if s.recorder == nil {
+		return nil
+	}
+	meta := input.SnapshotMeta{
+		CapturedAt:    s.capturedAt,
+		Args:          args,
+		Namespace:     v.GetString("namespace"),
+		AllNamespaces: v.GetBool("all-namespaces"),
+		Selector:      v.GetString("selector"),
+	}
+	return s.recorder.WriteFile(v.GetString("snapshot-out"), meta)
+}
+
+func (s *snapshotSession) cleanup() {
+	if s.cacheDir == "" {
+		return
+	}
+	if err := os.RemoveAll(s.cacheDir); err != nil {
+		klog.V(2).ErrorS(err, "failed to remove snapshot discovery cache", "dir", s.cacheDir)
+	}
+}
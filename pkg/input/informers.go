@@ -0,0 +1,236 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// InformerResources are the related kinds templates look up most while rendering -- a workload's
+// Pods and ReplicaSets, a Pod's EndpointSlices, everyone's Events -- and so the ones StartInformers
+// keeps a local copy of.
+var InformerResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "pods"},
+	{Version: "v1", Resource: "events"},
+	{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"},
+	{Group: "apps", Version: "v1", Resource: "replicasets"},
+}
+
+var (
+	eventsGVR         = InformerResources[1]
+	endpointSlicesGVR = InformerResources[2]
+)
+
+// relatedInformers are the running informers behind StartInformers, all scoped to one namespace
+// (or every namespace, when that is "").
+type relatedInformers struct {
+	namespace string
+	stores    map[schema.GroupVersionResource]cache.Indexer
+}
+
+// covers reports whether the informers hold every object of gvr in namespace.
+func (i *relatedInformers) covers(gvr schema.GroupVersionResource, namespace string) (cache.Indexer, bool) {
+	if i == nil {
+		return nil, false
+	}
+	store, ok := i.stores[gvr]
+	if !ok || (i.namespace != "" && namespace != i.namespace) {
+		return nil, false
+	}
+	return store, true
+}
+
+// list returns copies of the objects in store within namespace ("" for all) matching selector,
+// sorted by creation time like objectsUncached's result.
+func (i *relatedInformers) list(store cache.Indexer, namespace string, selector labels.Selector) Objects {
+	var items []interface{}
+	if namespace == "" {
+		items = store.List()
+	} else {
+		items, _ = store.ByIndex(cache.NamespaceIndex, namespace)
+	}
+	objects := Objects{}
+	for _, item := range items {
+		u, ok := item.(*unstructured.Unstructured)
+		if !ok || !selector.Matches(labels.Set(u.GetLabels())) {
+			continue
+		}
+		objects = append(objects, u.DeepCopy().Object)
+	}
+	sort.Stable(objects)
+	return objects
+}
+
+func (i *relatedInformers) get(store cache.Indexer, gvr schema.GroupVersionResource, namespace, name string) (Object, error) {
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	item, exists, err := store.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	return item.(*unstructured.Unstructured).DeepCopy().Object, nil
+}
+
+// StartInformers starts informers for InformerResources in namespace ("" for every namespace) and
+// waits for their initial lists. From then on, until ctx is done, the repo answers lookups of
+// those kinds from the informers' local stores instead of the API server -- which also means
+// from fresh data rather than from its per-render caches -- and onChange is called, from the
+// informers' goroutines, with every object added, updated or deleted after that initial list.
+func (r *ResourceRepo) StartInformers(ctx context.Context, namespace string, onChange func(gvr schema.GroupVersionResource, obj Object)) error {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(r.dynamicClient, 0, namespace, nil)
+	informers := &relatedInformers{namespace: namespace, stores: map[schema.GroupVersionResource]cache.Indexer{}}
+	for _, gvr := range InformerResources {
+		informer := factory.ForResource(gvr).Informer()
+		notify := func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				onChange(gvr, u.Object)
+			}
+		}
+		_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj interface{}, isInInitialList bool) {
+				if !isInInitialList {
+					notify(obj)
+				}
+			},
+			UpdateFunc: func(_, obj interface{}) { notify(obj) },
+			DeleteFunc: notify,
+		})
+		if err != nil {
+			return err
+		}
+		informers.stores[gvr] = informer.GetIndexer()
+	}
+	factory.Start(ctx.Done())
+	for gvr, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync the %s informer", gvr.GroupResource())
+		}
+	}
+	klog.V(3).InfoS("Informers synced", "namespace", namespace, "resources", len(InformerResources))
+	r.cacheMu.Lock()
+	r.informers = informers
+	r.cacheMu.Unlock()
+	return nil
+}
+
+func (r *ResourceRepo) runningInformers() *relatedInformers {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	return r.informers
+}
+
+// informerObjects answers Objects for a single TYPE (optionally with one NAME) of an informer-backed
+// kind; ok is false when the informers can't answer it and the caller should query as usual.
+func (r *ResourceRepo) informerObjects(namespace string, args []string, labelSelector string) (objects Objects, ok bool, err error) {
+	informers := r.runningInformers()
+	if informers == nil || len(args) == 0 || len(args) > 2 {
+		return nil, false, nil
+	}
+	mapping, err := r.mappingFor(args[0])
+	if err != nil {
+		return nil, false, nil
+	}
+	store, ok := informers.covers(mapping.Resource, namespace)
+	if !ok {
+		return nil, false, nil
+	}
+	if len(args) == 2 {
+		object, err := informers.get(store, mapping.Resource, namespace, args[1])
+		if err != nil {
+			return nil, true, err
+		}
+		return Objects{object}, true, nil
+	}
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, true, err
+	}
+	return informers.list(store, namespace, selector), true, nil
+}
+
+// informerObject answers a get of gvr namespace/name from the informers; ok is false when they
+// can't.
+func (r *ResourceRepo) informerObject(gvr schema.GroupVersionResource, namespace, name string) (object Object, ok bool, err error) {
+	informers := r.runningInformers()
+	store, ok := informers.covers(gvr, namespace)
+	if !ok {
+		return nil, false, nil
+	}
+	object, err = informers.get(store, gvr, namespace, name)
+	return object, true, err
+}
+
+func (r *ResourceRepo) informerEndpointSlices(namespace string) (*discoveryv1.EndpointSliceList, bool) {
+	informers := r.runningInformers()
+	store, ok := informers.covers(endpointSlicesGVR, namespace)
+	if !ok {
+		return nil, false
+	}
+	list := &discoveryv1.EndpointSliceList{}
+	for _, object := range informers.list(store, namespace, labels.Everything()) {
+		var slice discoveryv1.EndpointSlice
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, &slice); err != nil {
+			klog.V(3).ErrorS(err, "failed to convert EndpointSlice from informer")
+			continue
+		}
+		list.Items = append(list.Items, slice)
+	}
+	return list, true
+}
+
+func (r *ResourceRepo) informerEvents(namespace string) (*corev1.EventList, bool) {
+	informers := r.runningInformers()
+	store, ok := informers.covers(eventsGVR, namespace)
+	if !ok || namespace == "" {
+		return nil, false
+	}
+	list := &corev1.EventList{}
+	for _, object := range informers.list(store, namespace, labels.Everything()) {
+		var event corev1.Event
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, &event); err != nil {
+			klog.V(3).ErrorS(err, "failed to convert Event from informer")
+			continue
+		}
+		list.Items = append(list.Items, event)
+	}
+	return list, true
+}
+
+// ResetCaches forgets every cached lookup, so the next render sees the cluster as it is now.
+// Lookups the informers answer are never cached in the first place.
+func (r *ResourceRepo) ResetCaches() {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	r.nodeStatsSummaryCache = make(map[string]nodeStatsSummaryCacheEntry)
+	r.nodeConfigzCache = make(map[string]nodeConfigzCacheEntry)
+	r.nodeHealthzCache = make(map[string]nodeHealthzCacheEntry)
+	r.workloadNodeCache = make(map[string]workloadNodeCacheEntry)
+	r.cnpgStatusCache = make(map[string]cnpgStatusCacheEntry)
+	r.objectsCache = make(map[string]objectsCacheEntry)
+	r.endpointSlicesCache = make(map[string]endpointSlicesCacheEntry)
+	r.ownerCache = make(map[string]ownerCacheEntry)
+	r.servicesCache = make(map[string]servicesCacheEntry)
+	r.ingressesCache = make(map[string]ingressesCacheEntry)
+	r.eventsCache = make(map[string]eventsCacheEntry)
+	r.allNamespacesPodMetricsCache = nil
+	r.metricsUnavailableReasonCache = nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
@@ -18,15 +20,26 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/cmd/events"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
+// workloadClusterTimeout bounds each request WorkloadClusterNode makes to a workload cluster.
+const workloadClusterTimeout = 5 * time.Second
+
+// cnpgInstanceStatusTimeout bounds each request CNPGInstanceStatus proxies to an instance
+// manager, so one hung Pod can't hold up the render.
+const cnpgInstanceStatusTimeout = 5 * time.Second
+
 // Object is the JSON compatible map[string]interface{} mostly used through unstructured.Unstructured.
 type Object map[string]interface{}
 
@@ -77,14 +90,22 @@ func NewResourceRepo(factory util.Factory, v *viper.Viper) (*ResourceRepo, error
 		objectsCache:          make(map[string]objectsCacheEntry),
 		endpointSlicesCache:   make(map[string]endpointSlicesCacheEntry),
 		ownerCache:            make(map[string]ownerCacheEntry),
+		servicesCache:         make(map[string]servicesCacheEntry),
+		ingressesCache:        make(map[string]ingressesCacheEntry),
+		eventsCache:           make(map[string]eventsCacheEntry),
+		workloadNodeCache:     make(map[string]workloadNodeCacheEntry),
+		cnpgStatusCache:       make(map[string]cnpgStatusCacheEntry),
 	}, nil
 }
 
 type ResourceRepo struct {
-	f                             util.Factory
-	viper                         *viper.Viper
-	dynamicClient                 dynamic.Interface
-	kubernetesClientSet           *kubernetes.Clientset
+	f                   util.Factory
+	viper               *viper.Viper
+	dynamicClient       dynamic.Interface
+	kubernetesClientSet *kubernetes.Clientset
+	// cacheMu guards every cache below. Rendering itself is single-threaded, but Prefetch fills
+	// the same caches from several goroutines at once. It is never held across an API call.
+	cacheMu                       sync.Mutex
 	nodeStatsSummaryCache         map[string]nodeStatsSummaryCacheEntry
 	nodeConfigzCache              map[string]nodeConfigzCacheEntry
 	nodeHealthzCache              map[string]nodeHealthzCacheEntry
@@ -93,6 +114,20 @@ type ResourceRepo struct {
 	allNamespacesPodMetricsCache  *objectsCacheEntry
 	ownerCache                    map[string]ownerCacheEntry
 	metricsUnavailableReasonCache *string
+	servicesCache                 map[string]servicesCacheEntry
+	ingressesCache                map[string]ingressesCacheEntry
+	// eventsCache is only ever filled by PrefetchEvents; ObjectEvents falls back to a per-object
+	// search for any namespace that wasn't prefetched.
+	eventsCache map[string]eventsCacheEntry
+	// workloadNodeCache holds Nodes fetched from Cluster API workload clusters, keyed by
+	// "<namespace>/<cluster>/<node>".
+	workloadNodeCache map[string]workloadNodeCacheEntry
+	// cnpgStatusCache holds CloudNativePG instance manager status reports, keyed by
+	// "<namespace>/<pod>".
+	cnpgStatusCache map[string]cnpgStatusCacheEntry
+	// informers, once StartInformers has run, answer lookups of InformerResources ahead of any
+	// cache.
+	informers *relatedInformers
 }
 
 type nodeStatsSummaryCacheEntry struct {
@@ -125,6 +160,31 @@ type ownerCacheEntry struct {
 	err    error
 }
 
+type servicesCacheEntry struct {
+	list *corev1.ServiceList
+	err  error
+}
+
+type ingressesCacheEntry struct {
+	list *netv1.IngressList
+	err  error
+}
+
+type eventsCacheEntry struct {
+	list *corev1.EventList
+	err  error
+}
+
+type workloadNodeCacheEntry struct {
+	node Object
+	err  error
+}
+
+type cnpgStatusCacheEntry struct {
+	status Object
+	err    error
+}
+
 func (r *ResourceRepo) newBaseBuilder() *resource.Builder {
 	builder := r.f.NewBuilder().
 		NamespaceParam(r.viper.GetString("namespace")).
@@ -163,6 +223,61 @@ func (r *ResourceRepo) CLIQueryResults(args []string) *resource.Result {
 	return builder.Do()
 }
 
+// ListableResourceTypes returns one "resource.group" argument (bare "resource" for the core
+// group) for every namespaced resource type the server lets us list, in the server's preferred
+// version -- what `k status --problems` without any TYPE argument sweeps. Discovery failures for
+// individual groups (typically a broken aggregated API such as an unhealthy metrics-server) are
+// logged and skipped, the same way kubectl's own api-resources does, rather than failing the
+// whole sweep over one group nobody asked about.
+func (r *ResourceRepo) ListableResourceTypes() ([]string, error) {
+	discoveryClient, err := r.f.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	lists, err := discovery.ServerPreferredNamespacedResources(discoveryClient)
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) || len(lists) == 0 {
+			return nil, err
+		}
+		klog.V(2).InfoS("ignoring partial discovery failure", "err", err)
+	}
+	return listableResourceArgs(lists), nil
+}
+
+// listableResourceArgs flattens discovery output into resource-builder arguments, dropping
+// subresources, anything without a "list" verb, and Events -- which are already shown under the
+// object they involve, never have a kstatus of their own to disagree with Current, and are by far
+// the biggest list in any busy namespace.
+func listableResourceArgs(lists []*metav1.APIResourceList) []string {
+	var args []string
+	seen := map[string]bool{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range list.APIResources {
+			if strings.Contains(apiResource.Name, "/") || !sets.New(apiResource.Verbs...).Has("list") {
+				continue
+			}
+			if apiResource.Name == "events" {
+				continue
+			}
+			arg := apiResource.Name
+			if gv.Group != "" {
+				arg = apiResource.Name + "." + gv.Group
+			}
+			if seen[arg] {
+				continue
+			}
+			seen[arg] = true
+			args = append(args, arg)
+		}
+	}
+	sort.Strings(args)
+	return args
+}
+
 // resolvePartialNameArgs implements `k status TYPE name` matching by substring when the exact
 // name doesn't exist, e.g. `k status deploy component` matching Deployment/myapp-mycomponent.
 // It only handles the common single-type "TYPE name..." shape (as opposed to "TYPE/NAME" or
@@ -230,11 +345,19 @@ func (r *ResourceRepo) namesContaining(gvr schema.GroupVersionResource, namespac
 }
 
 func (r *ResourceRepo) Objects(namespace string, args []string, labelSelector string) (Objects, error) {
+	if objects, ok, err := r.informerObjects(namespace, args, labelSelector); ok {
+		return objects, err
+	}
 	cacheKey := strings.Join([]string{namespace, strings.Join(args, "\x1f"), labelSelector}, "\x1e")
-	if entry, ok := r.objectsCache[cacheKey]; ok {
+	r.cacheMu.Lock()
+	entry, ok := r.objectsCache[cacheKey]
+	r.cacheMu.Unlock()
+	if ok {
 		return entry.objects, entry.err
 	}
 	unstructuredObjects, err := r.objectsUncached(namespace, args, labelSelector)
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	if r.objectsCache == nil {
 		r.objectsCache = make(map[string]objectsCacheEntry)
 	}
@@ -265,8 +388,11 @@ func (r *ResourceRepo) objectsUncached(namespace string, args []string, labelSel
 // printed once and callers should fall back to per-namespace fetches, which may in turn be
 // incomplete for namespaces the user also can't access.
 func (r *ResourceRepo) AllNamespacesPodMetrics() (Objects, error) {
-	if r.allNamespacesPodMetricsCache != nil {
-		return r.allNamespacesPodMetricsCache.objects, r.allNamespacesPodMetricsCache.err
+	r.cacheMu.Lock()
+	cached := r.allNamespacesPodMetricsCache
+	r.cacheMu.Unlock()
+	if cached != nil {
+		return cached.objects, cached.err
 	}
 	builder := r.newBaseBuilder().
 		NamespaceParam("").
@@ -284,7 +410,9 @@ func (r *ResourceRepo) AllNamespacesPodMetrics() (Objects, error) {
 		}
 		sort.Sort(objects)
 	}
+	r.cacheMu.Lock()
 	r.allNamespacesPodMetricsCache = &objectsCacheEntry{objects: objects, err: err}
+	r.cacheMu.Unlock()
 	return objects, err
 }
 
@@ -305,8 +433,11 @@ var metricsAPIServiceGVR = schema.GroupVersionResource{Group: "apiregistration.k
 //
 // The result is cached since Pod/Node rendering checks this repeatedly.
 func (r *ResourceRepo) MetricsUnavailableReason() string {
-	if r.metricsUnavailableReasonCache != nil {
-		return *r.metricsUnavailableReasonCache
+	r.cacheMu.Lock()
+	cached := r.metricsUnavailableReasonCache
+	r.cacheMu.Unlock()
+	if cached != nil {
+		return *cached
 	}
 	reason := ""
 	obj, err := r.DynamicObject(metricsAPIServiceGVR, "", "v1beta1.metrics.k8s.io")
@@ -319,7 +450,9 @@ func (r *ResourceRepo) MetricsUnavailableReason() string {
 	default:
 		reason = unavailableReasonFromAPIServiceConditions(obj)
 	}
+	r.cacheMu.Lock()
 	r.metricsUnavailableReasonCache = &reason
+	r.cacheMu.Unlock()
 	return reason
 }
 
@@ -349,7 +482,7 @@ func (r *ResourceRepo) Owners(obj Object) (owners Objects, orphans []metav1.Owne
 	uobj := obj.Unstructured()
 	namespace := uobj.GetNamespace()
 	for _, owner := range uobj.GetOwnerReferences() {
-		object, err := r.resolveOwner(namespace, owner)
+		object, err := r.ResolveOwner(namespace, owner)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				orphans = append(orphans, owner)
@@ -363,11 +496,11 @@ func (r *ResourceRepo) Owners(obj Object) (owners Objects, orphans []metav1.Owne
 	return owners, orphans, nil
 }
 
-// resolveOwner fetches the object referenced by an ownerReference, using the dynamic client
+// ResolveOwner fetches the object referenced by an ownerReference, using the dynamic client
 // directly so that Kubernetes API errors (e.g. NotFound) reach the caller unwrapped. Cluster-scoped
 // owners are looked up without a namespace, since the dynamic client is not scope-aware and would
 // otherwise build a namespaced request URL for them and wrongly get back a NotFound.
-func (r *ResourceRepo) resolveOwner(namespace string, owner metav1.OwnerReference) (Object, error) {
+func (r *ResourceRepo) ResolveOwner(namespace string, owner metav1.OwnerReference) (Object, error) {
 	mapping, err := r.ownerReferenceMapping(owner)
 	if err != nil {
 		return nil, err
@@ -375,11 +508,19 @@ func (r *ResourceRepo) resolveOwner(namespace string, owner metav1.OwnerReferenc
 	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
 		namespace = ""
 	}
+	if object, ok, err := r.informerObject(mapping.Resource, namespace, owner.Name); ok {
+		return object, err
+	}
 	cacheKey := strings.Join([]string{namespace, mapping.Resource.String(), owner.Name}, "\x1e")
-	if entry, ok := r.ownerCache[cacheKey]; ok {
+	r.cacheMu.Lock()
+	entry, ok := r.ownerCache[cacheKey]
+	r.cacheMu.Unlock()
+	if ok {
 		return entry.object, entry.err
 	}
 	object, err := r.DynamicObject(mapping.Resource, namespace, owner.Name)
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	if r.ownerCache == nil {
 		r.ownerCache = make(map[string]ownerCacheEntry)
 	}
@@ -419,6 +560,15 @@ func (r *ResourceRepo) FirstObject(namespace string, args []string, labelSelecto
 }
 
 func (r *ResourceRepo) ObjectEvents(u *unstructured.Unstructured) (*corev1.EventList, error) {
+	namespaceEvents, ok := r.informerEvents(u.GetNamespace())
+	if !ok {
+		namespaceEvents, ok = r.prefetchedEvents(u.GetNamespace())
+	}
+	if ok {
+		eventList := filterObjectEvents(namespaceEvents, u)
+		sort.Sort(events.SortableEvents(eventList.Items))
+		return eventList, nil
+	}
 	eventList, err := r.kubernetesClientSet.CoreV1().Events(u.GetNamespace()).SearchWithContext(context.TODO(), scheme.Scheme, u)
 	if err != nil {
 		klog.V(3).ErrorS(err, "error getting events", "r", r)
@@ -428,6 +578,38 @@ func (r *ResourceRepo) ObjectEvents(u *unstructured.Unstructured) (*corev1.Event
 	return eventList, nil
 }
 
+// prefetchedEvents returns the namespace's Events list PrefetchEvents fetched; ok is false when
+// there is no usable one.
+func (r *ResourceRepo) prefetchedEvents(namespace string) (*corev1.EventList, bool) {
+	r.cacheMu.Lock()
+	entry, ok := r.eventsCache[namespace]
+	r.cacheMu.Unlock()
+	if !ok || entry.err != nil {
+		return nil, false
+	}
+	return entry.list, true
+}
+
+// filterObjectEvents narrows a namespace's Events down to those a SearchWithContext for u would
+// have returned: same involvedObject name, namespace and kind, and the same UID when u has one.
+func filterObjectEvents(namespaceEvents *corev1.EventList, u *unstructured.Unstructured) *corev1.EventList {
+	eventList := &corev1.EventList{}
+	for _, event := range namespaceEvents.Items {
+		involved := event.InvolvedObject
+		if involved.Name != u.GetName() || involved.Namespace != u.GetNamespace() {
+			continue
+		}
+		if u.GetKind() != "" && involved.Kind != u.GetKind() {
+			continue
+		}
+		if u.GetUID() != "" && involved.UID != u.GetUID() {
+			continue
+		}
+		eventList.Items = append(eventList.Items, event)
+	}
+	return eventList
+}
+
 // PodContainerLogs returns up to tailLines lines of log output for the named container in the
 // named pod. When previous is true it fetches logs from the container's previous (terminated)
 // instance, equivalent to `kubectl logs --previous`.
@@ -516,22 +698,71 @@ func (r *ResourceRepo) mappingFor(resourceOrKindArg string) (*meta.RESTMapping,
 }
 
 func (r *ResourceRepo) Ingresses(namespace string) (*netv1.IngressList, error) {
-	return r.kubernetesClientSet.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	r.cacheMu.Lock()
+	entry, ok := r.ingressesCache[namespace]
+	r.cacheMu.Unlock()
+	if ok {
+		return entry.list, entry.err
+	}
+	list, err := r.kubernetesClientSet.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	if r.ingressesCache == nil {
+		r.ingressesCache = make(map[string]ingressesCacheEntry)
+	}
+	r.ingressesCache[namespace] = ingressesCacheEntry{list: list, err: err}
+	return list, err
 }
 
 func (r *ResourceRepo) Services(namespace string) (*corev1.ServiceList, error) {
-	return r.kubernetesClientSet.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	r.cacheMu.Lock()
+	entry, ok := r.servicesCache[namespace]
+	r.cacheMu.Unlock()
+	if ok {
+		return entry.list, entry.err
+	}
+	list, err := r.kubernetesClientSet.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	if r.servicesCache == nil {
+		r.servicesCache = make(map[string]servicesCacheEntry)
+	}
+	r.servicesCache[namespace] = servicesCacheEntry{list: list, err: err}
+	return list, err
 }
 
+// Service is answered from the namespace's Services list when that has already been fetched
+// (e.g. by Prefetch), and with a single GET otherwise; a name missing from a fetched list is
+// reported as the same NotFound the GET would have returned.
 func (r *ResourceRepo) Service(namespace, name string) (*corev1.Service, error) {
+	r.cacheMu.Lock()
+	entry, ok := r.servicesCache[namespace]
+	r.cacheMu.Unlock()
+	if ok && entry.err == nil {
+		for i := range entry.list.Items {
+			if entry.list.Items[i].Name == name {
+				svc := entry.list.Items[i]
+				return &svc, nil
+			}
+		}
+		return nil, apierrors.NewNotFound(corev1.Resource("services"), name)
+	}
 	return r.kubernetesClientSet.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
 func (r *ResourceRepo) EndpointSlices(namespace string) (*discoveryv1.EndpointSliceList, error) {
-	if entry, ok := r.endpointSlicesCache[namespace]; ok {
+	if list, ok := r.informerEndpointSlices(namespace); ok {
+		return list, nil
+	}
+	r.cacheMu.Lock()
+	entry, ok := r.endpointSlicesCache[namespace]
+	r.cacheMu.Unlock()
+	if ok {
 		return entry.list, entry.err
 	}
 	list, err := r.kubernetesClientSet.DiscoveryV1().EndpointSlices(namespace).List(context.TODO(), metav1.ListOptions{})
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	if r.endpointSlicesCache == nil {
 		r.endpointSlicesCache = make(map[string]endpointSlicesCacheEntry)
 	}
@@ -539,14 +770,65 @@ func (r *ResourceRepo) EndpointSlices(namespace string) (*discoveryv1.EndpointSl
 	return list, err
 }
 
+// prefetchConcurrency bounds how many requests Prefetch keeps in flight. client-go's own
+// QPS/burst limiter still applies on top; this just keeps a namespace with hundreds of objects
+// from spawning hundreds of goroutines that would all queue on it anyway.
+const prefetchConcurrency = 8
+
+// Prefetch runs loads concurrently, at most prefetchConcurrency at a time, and returns once all
+// of them have finished. Each load is expected to call one of the repo's own cached getters
+// (Objects, Services, EndpointSlices, PrefetchEvents, Owners, ...) and discard the result: the
+// point is only to have the answer sitting in the cache -- errors included -- by the time a
+// template asks the same question, so the render that follows doesn't issue the same requests
+// one at a time. Callers should pass each distinct load once; two loads racing for the same
+// cache key both hit the API.
+func (r *ResourceRepo) Prefetch(loads []func()) {
+	sem := make(chan struct{}, prefetchConcurrency)
+	var wg sync.WaitGroup
+	for _, load := range loads {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			load()
+		}()
+	}
+	wg.Wait()
+}
+
+// PrefetchEvents lists every Event in namespace once, so that ObjectEvents can answer for any
+// object in it without a per-object search. Worth it only when many objects of the same
+// namespace are about to be rendered, which is why ObjectEvents never does this on its own.
+func (r *ResourceRepo) PrefetchEvents(namespace string) {
+	r.cacheMu.Lock()
+	_, ok := r.eventsCache[namespace]
+	r.cacheMu.Unlock()
+	if ok || namespace == "" {
+		return
+	}
+	list, err := r.kubernetesClientSet.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	if r.eventsCache == nil {
+		r.eventsCache = make(map[string]eventsCacheEntry)
+	}
+	r.eventsCache[namespace] = eventsCacheEntry{list: list, err: err}
+}
+
 // KubeGetNodeStatsSummary returns this structure
 // > kubectl get --raw /api/v1/nodes/{nodeName}/proxy/stats/summary
 // The endpoint that this function uses will be disabled soon: https://github.com/kubernetes/kubernetes/issues/68522
 func (r *ResourceRepo) KubeGetNodeStatsSummary(nodeName string) (Object, error) {
-	if entry, ok := r.nodeStatsSummaryCache[nodeName]; ok {
+	r.cacheMu.Lock()
+	entry, ok := r.nodeStatsSummaryCache[nodeName]
+	r.cacheMu.Unlock()
+	if ok {
 		return entry.summary, entry.err
 	}
 	nodeStatsSummary, err := r.kubeGetNodeStatsSummaryUncached(nodeName)
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	if r.nodeStatsSummaryCache == nil {
 		r.nodeStatsSummaryCache = make(map[string]nodeStatsSummaryCacheEntry)
 	}
@@ -574,10 +856,15 @@ func (r *ResourceRepo) kubeGetNodeStatsSummaryUncached(nodeName string) (Object,
 // This surfaces the effective KubeletConfiguration, including settings not otherwise visible on the
 // Node object (eviction thresholds, per-node QoS manager policies, pids limits, etc).
 func (r *ResourceRepo) KubeGetNodeConfigz(nodeName string) (Object, error) {
-	if entry, ok := r.nodeConfigzCache[nodeName]; ok {
+	r.cacheMu.Lock()
+	entry, ok := r.nodeConfigzCache[nodeName]
+	r.cacheMu.Unlock()
+	if ok {
 		return entry.configz, entry.err
 	}
 	nodeConfigz, err := r.kubeGetNodeConfigzUncached(nodeName)
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	if r.nodeConfigzCache == nil {
 		r.nodeConfigzCache = make(map[string]nodeConfigzCacheEntry)
 	}
@@ -607,10 +894,15 @@ func (r *ResourceRepo) kubeGetNodeConfigzUncached(nodeName string) (Object, erro
 // kubectl logs/exec/attach, metrics-server and any node-hosted admission webhooks. A Ready node can
 // still fail this check if that path is blocked (e.g. port 10250 unreachable).
 func (r *ResourceRepo) KubeGetNodeHealthz(nodeName string) (string, error) {
-	if entry, ok := r.nodeHealthzCache[nodeName]; ok {
+	r.cacheMu.Lock()
+	entry, ok := r.nodeHealthzCache[nodeName]
+	r.cacheMu.Unlock()
+	if ok {
 		return entry.healthz, entry.err
 	}
 	nodeHealthz, err := r.kubeGetNodeHealthzUncached(nodeName)
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	if r.nodeHealthzCache == nil {
 		r.nodeHealthzCache = make(map[string]nodeHealthzCacheEntry)
 	}
@@ -631,6 +923,123 @@ func (r *ResourceRepo) kubeGetNodeHealthzUncached(nodeName string) (string, erro
 	return string(getBytes), nil
 }
 
+// WorkloadClusterNode fetches a Node from a Cluster API workload cluster rather than the cluster
+// kubectl is pointed at, using the admin kubeconfig Cluster API keeps for it in the
+// "<cluster>-kubeconfig" Secret next to the Cluster. A workload cluster that is still
+// provisioning, or just not routable from here, is common enough that the request gets a short
+// timeout of its own instead of the caller's.
+func (r *ResourceRepo) WorkloadClusterNode(namespace, clusterName, nodeName string) (Object, error) {
+	key := namespace + "/" + clusterName + "/" + nodeName
+	r.cacheMu.Lock()
+	entry, ok := r.workloadNodeCache[key]
+	r.cacheMu.Unlock()
+	if ok {
+		return entry.node, entry.err
+	}
+	node, err := r.workloadClusterNodeUncached(namespace, clusterName, nodeName)
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	if r.workloadNodeCache == nil {
+		r.workloadNodeCache = make(map[string]workloadNodeCacheEntry)
+	}
+	r.workloadNodeCache[key] = workloadNodeCacheEntry{node: node, err: err}
+	return node, err
+}
+
+func (r *ResourceRepo) workloadClusterNodeUncached(namespace, clusterName, nodeName string) (Object, error) {
+	secret, err := r.kubernetesClientSet.CoreV1().Secrets(namespace).Get(context.TODO(), clusterName+"-kubeconfig", metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data["value"])
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig Secret %s/%s-kubeconfig: %w", namespace, clusterName, err)
+	}
+	config.Timeout = workloadClusterTimeout
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	node, err := clientSet.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	node.Kind = "Node"
+	node.APIVersion = "v1"
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(node)
+}
+
+// CNPGInstanceStatus returns the status report a CloudNativePG instance manager serves on port
+// 8000 of its Pod, the way `kubectl cnpg status` reads it:
+// > kubectl get --raw /api/v1/namespaces/{namespace}/pods/https:{podName}:8000/proxy/pg/status
+// It is PostgreSQL's own view of the instance -- WAL positions, pg_stat_replication for each
+// standby, pg_stat_archiver -- none of which CloudNativePG copies into the Cluster's status.
+// Older operator versions serve it over plain HTTP, so that is tried when HTTPS fails on the
+// scheme itself; a Pod that times out or refuses the connection isn't tried again.
+func (r *ResourceRepo) CNPGInstanceStatus(namespace, podName string) (Object, error) {
+	key := namespace + "/" + podName
+	r.cacheMu.Lock()
+	entry, ok := r.cnpgStatusCache[key]
+	r.cacheMu.Unlock()
+	if ok {
+		return entry.status, entry.err
+	}
+	status, err := r.cnpgInstanceStatusUncached(namespace, podName, "https")
+	if isSchemeMismatchError(err) {
+		if httpStatus, httpErr := r.cnpgInstanceStatusUncached(namespace, podName, "http"); httpErr == nil {
+			status, err = httpStatus, nil
+		}
+	}
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	if r.cnpgStatusCache == nil {
+		r.cnpgStatusCache = make(map[string]cnpgStatusCacheEntry)
+	}
+	r.cnpgStatusCache[key] = cnpgStatusCacheEntry{status: status, err: err}
+	return status, err
+}
+
+func (r *ResourceRepo) cnpgInstanceStatusUncached(namespace, podName, scheme string) (Object, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cnpgInstanceStatusTimeout)
+	defer cancel()
+	// Do rather than DoRaw (which ProxyGet is limited to): only Result.Error decodes the proxy's
+	// own error message, which isSchemeMismatchError reads.
+	result := r.kubernetesClientSet.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("pods").
+		SubResource("proxy").
+		Name(net.JoinSchemeNamePort(scheme, podName, "8000")).
+		Suffix("pg/status").
+		Do(ctx)
+	getBytes, err := result.Raw()
+	if err != nil {
+		return nil, result.Error()
+	}
+	status := make(Object)
+	err = json.Unmarshal(getBytes, &status)
+	return status, err
+}
+
+// isSchemeMismatchError reports whether err is the API server's proxy failing on HTTPS because
+// the other end speaks plain HTTP (or TLS otherwise failed to set up). The proxy only passes
+// these back as text in its error message.
+func isSchemeMismatchError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	for _, marker := range []string{
+		"server gave HTTP response to HTTPS client",
+		"first record does not look like a TLS handshake",
+		"tls: ",
+	} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *ResourceRepo) NonTerminatedPodsOnTheNode(nodeName string) (Objects, error) {
 	fieldSelector, err := fields.ParseSelector("spec.nodeName=" + nodeName +
 		",status.phase!=" + string(corev1.PodSucceeded) +
@@ -1,15 +1,26 @@
 package input
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest/fake"
@@ -351,6 +362,28 @@ func TestMetricsUnavailableReason(t *testing.T) {
 	})
 }
 
+func TestIsSchemeMismatchError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no error", nil, false},
+		{"plain HTTP behind https", errors.New(`error trying to reach service: http: server gave HTTP response to HTTPS client`), true},
+		{"not a TLS handshake", errors.New(`error trying to reach service: tls: first record does not look like a TLS handshake`), true},
+		{"TLS alert", errors.New(`error trying to reach service: remote error: tls: handshake failure`), true},
+		{"timeout", context.DeadlineExceeded, false},
+		{"refused", errors.New(`error trying to reach service: dial tcp 10.0.0.7:8000: connect: connection refused`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSchemeMismatchError(tt.err); got != tt.want {
+				t.Errorf("isSchemeMismatchError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestOwnersResolutionIsCached verifies that resolving the same ownerReference for two different
 // objects (e.g. two Pods owned by the same ReplicaSet) only performs a single API lookup.
 func TestOwnersResolutionIsCached(t *testing.T) {
@@ -404,3 +437,367 @@ func TestOwnersResolutionIsCached(t *testing.T) {
 		t.Errorf("expected owner resolution to be cached across objects (1 get action), got %d", getActions)
 	}
 }
+
+// TestListableResourceArgs verifies the TYPE list a bare `--problems` sweeps: core resources stay
+// unqualified, everything else gets its group so same-named resources in different groups don't
+// collide, and subresources, unlistable resources and Events (from either group) are dropped.
+func TestListableResourceArgs(t *testing.T) {
+	lists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Verbs: metav1.Verbs{"get", "list", "watch"}},
+				{Name: "pods/log", Verbs: metav1.Verbs{"get"}},
+				{Name: "events", Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "bindings", Verbs: metav1.Verbs{"create"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "deployments/scale", Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "events.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "events", Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "cluster.x-k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusters", Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "postgresql.cnpg.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusters", Verbs: metav1.Verbs{"list"}},
+			},
+		},
+	}
+	got := listableResourceArgs(lists)
+	want := []string{"clusters.cluster.x-k8s.io", "clusters.postgresql.cnpg.io", "deployments.apps", "pods"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("listableResourceArgs() = %v, want %v", got, want)
+	}
+}
+
+// newCoreV1TestFactory builds a test factory whose typed CoreV1 client answers every request with
+// the JSON encoding of responses["<METHOD> <path below /api/v1>"], or a 404 for anything else,
+// counting the requests it serves. A *metav1.Status response is served with its own Code.
+func newCoreV1TestFactory(t *testing.T, responses map[string]interface{}, requests *int32) *cmdtesting.TestFactory {
+	t.Helper()
+	f := cmdtesting.NewTestFactory().WithNamespace("test")
+	f.Client = &fake.RESTClient{
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(requests, 1)
+			header := http.Header{"Content-Type": []string{"application/json"}}
+			body, ok := responses[req.Method+" "+strings.TrimPrefix(req.URL.Path, "/api/v1")]
+			if !ok {
+				status := apierrors.NewNotFound(schema.GroupResource{}, req.URL.Path).ErrStatus
+				data, _ := json.Marshal(&status)
+				return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: io.NopCloser(bytes.NewReader(data))}, nil
+			}
+			data, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code := http.StatusOK
+			if status, isStatus := body.(*metav1.Status); isStatus {
+				code = int(status.Code)
+			}
+			return &http.Response{StatusCode: code, Header: header, Body: io.NopCloser(bytes.NewReader(data))}, nil
+		}),
+	}
+	f.UnstructuredClient = f.Client
+	t.Cleanup(func() { f.Cleanup() })
+	return f
+}
+
+// TestPrefetchBoundsConcurrency verifies that Prefetch runs every load, and never more than
+// prefetchConcurrency of them at once.
+func TestPrefetchBoundsConcurrency(t *testing.T) {
+	f := newTestFactory()
+	t.Cleanup(func() { f.Cleanup() })
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var running, maxRunning, done int32
+	var loads []func()
+	for i := 0; i < 5*prefetchConcurrency; i++ {
+		loads = append(loads, func() {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if now <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, now) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+	repo.Prefetch(loads)
+	if done != int32(len(loads)) {
+		t.Errorf("expected all %d loads to have run when Prefetch returned, got %d", len(loads), done)
+	}
+	if maxRunning > prefetchConcurrency {
+		t.Errorf("expected at most %d loads in flight, saw %d", prefetchConcurrency, maxRunning)
+	}
+}
+
+// TestServiceServedFromCachedList verifies that once a namespace's Services have been listed,
+// looking one up by name -- found or not -- doesn't go back to the apiserver.
+func TestServiceServedFromCachedList(t *testing.T) {
+	var requests int32
+	services := &corev1.ServiceList{Items: []corev1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"}}}}
+	f := newCoreV1TestFactory(t, map[string]interface{}{"GET /namespaces/test/services": services}, &requests)
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Services("test"); err != nil {
+		t.Fatal(err)
+	}
+	svc, err := repo.Service("test", "web")
+	if err != nil || svc.Name != "web" {
+		t.Errorf("expected Service web from the cached list, got %v, %v", svc, err)
+	}
+	if _, err := repo.Service("test", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound for a Service missing from the cached list, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected only the single list request, got %d requests", requests)
+	}
+}
+
+// TestObjectEventsFromPrefetchedNamespace verifies that after PrefetchEvents, ObjectEvents keeps
+// exactly the Events a per-object search would have returned -- same name, kind and UID -- without
+// issuing that search.
+func TestObjectEventsFromPrefetchedNamespace(t *testing.T) {
+	var requests int32
+	involved := func(kind, name, uid string) corev1.ObjectReference {
+		return corev1.ObjectReference{Kind: kind, Name: name, Namespace: "test", UID: types.UID(uid)}
+	}
+	eventList := &corev1.EventList{Items: []corev1.Event{
+		{ObjectMeta: metav1.ObjectMeta{Name: "e1"}, InvolvedObject: involved("Pod", "web-1", "uid-1"), Reason: "Pulled"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "e2"}, InvolvedObject: involved("Pod", "web-1", "uid-old"), Reason: "Killing"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "e3"}, InvolvedObject: involved("Pod", "web-2", "uid-2"), Reason: "Pulled"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "e4"}, InvolvedObject: involved("ReplicaSet", "web-1", "uid-rs"), Reason: "SuccessfulCreate"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "e5"}, InvolvedObject: involved("Pod", "web-1", "uid-1"), Reason: "Started"},
+	}}
+	f := newCoreV1TestFactory(t, map[string]interface{}{"GET /namespaces/test/events": eventList}, &requests)
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.PrefetchEvents("test")
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web-1", "namespace": "test", "uid": "uid-1"},
+	}}
+	events, err := repo.ObjectEvents(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reasons []string
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	if strings.Join(reasons, ",") != "Pulled,Started" {
+		t.Errorf("expected the Pulled and Started events of web-1 only, got %v", reasons)
+	}
+	if requests != 1 {
+		t.Errorf("expected only the namespace-wide list request, got %d requests", requests)
+	}
+}
+
+// TestStartInformers verifies that once the informers run, Objects, ResolveOwner and ObjectEvents
+// for the informer-backed kinds are answered from them -- the test factory's REST client can't
+// answer anything -- and that later changes are both reported to onChange and visible to the next
+// lookup.
+func TestStartInformers(t *testing.T) {
+	f := newTestFactory()
+	t.Cleanup(func() { f.Cleanup() })
+	pod := func(name string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "test", "labels": labels, "uid": name + "-uid"},
+		}}
+	}
+	rs := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "ReplicaSet",
+		"metadata":   map[string]interface{}{"name": "web-rs", "namespace": "test"},
+	}}
+	event := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "v1",
+		"kind":           "Event",
+		"metadata":       map[string]interface{}{"name": "web-1.1", "namespace": "test"},
+		"involvedObject": map[string]interface{}{"kind": "Pod", "name": "web-1", "namespace": "test", "uid": "web-1-uid"},
+		"reason":         "Started",
+	}}
+	f.FakeDynamicClient = fakedynamic.NewSimpleDynamicClient(scheme.Scheme,
+		pod("web-1", map[string]interface{}{"app": "web"}), pod("db-1", map[string]interface{}{"app": "db"}), rs, event)
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	changed := make(chan Object, 10)
+	err = repo.StartInformers(ctx, "test", func(_ schema.GroupVersionResource, obj Object) { changed <- obj })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pods, err := repo.Objects("test", []string{"pods"}, "app=web")
+	if err != nil || len(pods) != 1 || pods[0].Unstructured().GetName() != "web-1" {
+		t.Errorf("expected only web-1 from the informer, got %v, %v", pods, err)
+	}
+	if _, err := repo.ResolveOwner("test", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-rs"}); err != nil {
+		t.Errorf("expected the ReplicaSet owner from the informer, got %v", err)
+	}
+	if _, err := repo.ResolveOwner("test", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "gone"}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound for a ReplicaSet missing from the informer, got %v", err)
+	}
+	events, err := repo.ObjectEvents(pod("web-1", nil))
+	if err != nil || len(events.Items) != 1 || events.Items[0].Reason != "Started" {
+		t.Errorf("expected web-1's Started event from the informer, got %v, %v", events, err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("expected the initial list not to be reported as changes, got %d", len(changed))
+	}
+
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	if _, err := dynClient.Resource(podsGVR).Namespace("test").Create(ctx, pod("web-2", map[string]interface{}{"app": "web"}), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case obj := <-changed:
+		if name := obj.Unstructured().GetName(); name != "web-2" {
+			t.Errorf("expected the change to be web-2, got %s", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the informer to report the new Pod")
+	}
+	if pods, _ := repo.Objects("test", []string{"pods"}, "app=web"); len(pods) != 2 {
+		t.Errorf("expected both web Pods after the change, got %d", len(pods))
+	}
+}
+
+// TestWorkloadClusterNode verifies that WorkloadClusterNode reaches the workload cluster through the
+// kubeconfig in the "<cluster>-kubeconfig" Secret, returns the Node as an Object with its Kind set,
+// caches the answer, and reports a missing Secret as an error rather than an empty Node.
+func TestWorkloadClusterNode(t *testing.T) {
+	var workloadRequests int32
+	workload := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&workloadRequests, 1)
+		if req.URL.Path != "/api/v1/nodes/worker-0" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}})
+	}))
+	t.Cleanup(workload.Close)
+	kubeconfig := "apiVersion: v1\nkind: Config\ncurrent-context: admin\n" +
+		"clusters: [{name: workload, cluster: {server: " + workload.URL + "}}]\n" +
+		"users: [{name: admin, user: {token: secret}}]\n" +
+		"contexts: [{name: admin, context: {cluster: workload, user: admin}}]\n"
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-kubeconfig", Namespace: "test"},
+		Data:       map[string][]byte{"value": []byte(kubeconfig)},
+	}
+	var requests int32
+	f := newCoreV1TestFactory(t, map[string]interface{}{"GET /namespaces/test/secrets/prod-kubeconfig": secret}, &requests)
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		node, err := repo.WorkloadClusterNode("test", "prod", "worker-0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node["kind"] != "Node" || node["metadata"].(map[string]interface{})["name"] != "worker-0" {
+			t.Errorf("expected Node worker-0 from the workload cluster, got %v", node)
+		}
+	}
+	if requests != 1 || workloadRequests != 1 {
+		t.Errorf("expected a single Secret and Node request, got %d and %d", requests, workloadRequests)
+	}
+
+	if _, err := repo.WorkloadClusterNode("test", "staging", "worker-0"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound for a Cluster without a kubeconfig Secret, got %v", err)
+	}
+}
+
+// proxyError is the API server's answer when it can't reach a Pod it proxies to.
+func proxyError(message string) *metav1.Status {
+	return &metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  "error trying to reach service: " + message,
+		Reason:   metav1.StatusReasonServiceUnavailable,
+		Code:     http.StatusServiceUnavailable,
+	}
+}
+
+// TestCNPGInstanceStatusFallsBackToHTTP verifies that CNPGInstanceStatus reads an instance manager
+// that only serves plain HTTP, and caches the answer so the fallback isn't paid again.
+func TestCNPGInstanceStatusFallsBackToHTTP(t *testing.T) {
+	var requests int32
+	f := newCoreV1TestFactory(t, map[string]interface{}{
+		"GET /namespaces/test/pods/https:pg-1:8000/proxy/pg/status": proxyError("http: server gave HTTP response to HTTPS client"),
+		"GET /namespaces/test/pods/http:pg-1:8000/proxy/pg/status":  map[string]interface{}{"isPrimary": true, "currentLsn": "0/3000060"},
+	}, &requests)
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		status, err := repo.CNPGInstanceStatus("test", "pg-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status["currentLsn"] != "0/3000060" {
+			t.Errorf("expected the instance manager's status, got %v", status)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected one HTTPS and one HTTP request, got %d requests", requests)
+	}
+}
+
+// TestCNPGInstanceStatusOnlyRetriesSchemeErrors verifies that an instance manager that is simply
+// unreachable over HTTPS isn't asked again over HTTP.
+func TestCNPGInstanceStatusOnlyRetriesSchemeErrors(t *testing.T) {
+	var requests int32
+	f := newCoreV1TestFactory(t, map[string]interface{}{
+		"GET /namespaces/test/pods/https:pg-1:8000/proxy/pg/status": proxyError("dial tcp 10.0.0.7:8000: connect: connection refused"),
+		"GET /namespaces/test/pods/http:pg-1:8000/proxy/pg/status":  map[string]interface{}{"isPrimary": true},
+	}, &requests)
+	repo, err := NewResourceRepo(f, viper.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CNPGInstanceStatus("test", "pg-1"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the HTTPS error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected a single HTTPS request, got %d requests", requests)
+	}
+}
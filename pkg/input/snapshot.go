@@ -0,0 +1,364 @@
+package input
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+// snapshotManifestName is the bundle member holding SnapshotMeta and the response index; every
+// other member is a response body under snapshotBodiesDir.
+const (
+	snapshotManifestName = "manifest.json"
+	snapshotBodiesDir    = "bodies/"
+	// snapshotFormatVersion is bumped whenever the bundle layout changes incompatibly, so an
+	// older binary refuses a newer bundle instead of misreading it.
+	snapshotFormatVersion = 1
+)
+
+// SnapshotMeta is what a bundle records about the invocation that captured it, so a replay can
+// reproduce the same query (and the same "... ago" durations) without the user having to
+// remember the original command line.
+type SnapshotMeta struct {
+	CapturedAt    time.Time `json:"capturedAt"`
+	Args          []string  `json:"args,omitempty"`
+	Namespace     string    `json:"namespace,omitempty"`
+	AllNamespaces bool      `json:"allNamespaces,omitempty"`
+	Selector      string    `json:"selector,omitempty"`
+}
+
+type snapshotManifest struct {
+	Version   int             `json:"version"`
+	Meta      SnapshotMeta    `json:"meta"`
+	Responses []snapshotEntry `json:"responses"`
+}
+
+// snapshotEntry is one recorded API response. Request is "<METHOD> <path>?<sorted query>":
+// the host is left out so a bundle replays regardless of which kubeconfig (if any) is loaded.
+type snapshotEntry struct {
+	Request     string `json:"request"`
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body"`
+
+	body []byte
+}
+
+func snapshotRequestKey(req *http.Request) string {
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	key := req.Method + " " + req.URL.Path
+	if len(parts) > 0 {
+		key += "?" + strings.Join(parts, "&")
+	}
+	return key
+}
+
+// SnapshotRecorder records every API response ResourceRepo's clients receive -- object gets and
+// lists, events, node proxy calls, metrics, logs, discovery -- by sitting in their
+// http.RoundTripper chain, which is the one place all of them pass through.
+type SnapshotRecorder struct {
+	mu      sync.Mutex
+	entries []snapshotEntry
+	seen    map[string]bool
+}
+
+func NewSnapshotRecorder() *SnapshotRecorder {
+	return &SnapshotRecorder{seen: map[string]bool{}}
+}
+
+// WrapConfig installs the recorder on config; it has the shape of
+// genericclioptions.ConfigFlags.WrapConfigFn.
+func (s *SnapshotRecorder) WrapConfig(config *rest.Config) *rest.Config {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return snapshotRecordingRoundTripper{recorder: s, next: rt}
+	})
+	return config
+}
+
+type snapshotRecordingRoundTripper struct {
+	recorder *SnapshotRecorder
+	next     http.RoundTripper
+}
+
+func (rt snapshotRecordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	recorded, ok := redactSnapshotSecrets(req.URL.Path, body)
+	if !ok {
+		klog.V(2).InfoS("not recording a Secret response that couldn't be redacted", "request", snapshotRequestKey(req))
+		return resp, nil
+	}
+	rt.recorder.add(snapshotEntry{
+		Request:     snapshotRequestKey(req),
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		body:        recorded,
+	})
+	return resp, nil
+}
+
+// redactSnapshotSecrets blanks every Secret value in body before it goes into a bundle, since
+// bundles get attached to incident tickets and a normal render reads pull secrets, TLS keys,
+// service-account tokens and Cluster API kubeconfigs. The keys stay, so a replay still shows
+// which ones a Secret has. A body from a secrets path that isn't JSON can't be checked, so ok is
+// false and it isn't recorded at all.
+func redactSnapshotSecrets(path string, body []byte) (redacted []byte, ok bool) {
+	isSecretsPath := slices.Contains(strings.Split(path, "/"), "secrets")
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return body, !isSecretsPath
+	}
+	changed := false
+	switch object["kind"] {
+	case "Secret":
+		changed = redactSecretObject(object)
+	case "SecretList", "List":
+		items, _ := object["items"].([]interface{})
+		for _, item := range items {
+			item, isMap := item.(map[string]interface{})
+			// SecretList items carry no kind of their own; a mixed List's do.
+			if isMap && (object["kind"] == "SecretList" || item["kind"] == "Secret") {
+				changed = redactSecretObject(item) || changed
+			}
+		}
+	}
+	if !changed {
+		return body, true
+	}
+	redacted, err := json.Marshal(object)
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+func redactSecretObject(secret map[string]interface{}) (changed bool) {
+	// kubectl apply keeps the whole applied manifest, values included, in this annotation.
+	if metadata, _ := secret["metadata"].(map[string]interface{}); metadata != nil {
+		if annotations, _ := metadata["annotations"].(map[string]interface{}); annotations != nil {
+			if _, found := annotations[corev1.LastAppliedConfigAnnotation]; found {
+				delete(annotations, corev1.LastAppliedConfigAnnotation)
+				changed = true
+			}
+		}
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, _ := secret[field].(map[string]interface{})
+		for key := range values {
+			values[key] = ""
+			changed = true
+		}
+	}
+	return changed
+}
+
+// add keeps the first response per request: with ResourceRepo's caches a repeat is rare, and
+// when it does happen the first answer is the one the render was built from.
+func (s *SnapshotRecorder) add(entry snapshotEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[entry.Request] {
+		return
+	}
+	s.seen[entry.Request] = true
+	s.entries = append(s.entries, entry)
+}
+
+// WriteFile writes everything recorded so far to path as a gzipped tarball.
+func (s *SnapshotRecorder) WriteFile(path string, meta SnapshotMeta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest := snapshotManifest{Version: snapshotFormatVersion, Meta: meta}
+	for i, entry := range s.entries {
+		entry.Body = fmt.Sprintf("%s%06d", snapshotBodiesDir, i)
+		manifest.Responses = append(manifest.Responses, entry)
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	writeMember := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: meta.CapturedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := writeMember(snapshotManifestName, manifestJSON); err != nil {
+		return err
+	}
+	for i, entry := range s.entries {
+		if err := writeMember(manifest.Responses[i].Body, entry.body); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// SnapshotReplayer answers API requests from a bundle written by SnapshotRecorder, never
+// touching the network.
+type SnapshotReplayer struct {
+	Meta      SnapshotMeta
+	responses map[string]snapshotEntry
+}
+
+// ReadSnapshotFile loads a bundle written by SnapshotRecorder.WriteFile.
+func ReadSnapshotFile(path string) (*SnapshotReplayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot %s: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+	members := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot %s: %w", path, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot %s: %w", path, err)
+		}
+		members[header.Name] = data
+	}
+	var manifest snapshotManifest
+	manifestJSON, ok := members[snapshotManifestName]
+	if !ok {
+		return nil, fmt.Errorf("reading snapshot %s: no %s, not a kubectl-status snapshot", path, snapshotManifestName)
+	}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("reading snapshot %s: %w", path, err)
+	}
+	if manifest.Version != snapshotFormatVersion {
+		return nil, fmt.Errorf("reading snapshot %s: unsupported snapshot version %d", path, manifest.Version)
+	}
+	replayer := &SnapshotReplayer{Meta: manifest.Meta, responses: map[string]snapshotEntry{}}
+	for _, entry := range manifest.Responses {
+		entry.body = members[entry.Body]
+		replayer.responses[entry.Request] = entry
+	}
+	return replayer, nil
+}
+
+// snapshotReplayHost is the API server address clients are pointed at during a replay. Nothing
+// ever dials it -- the replayer is the whole transport -- but it keeps a loaded kubeconfig's real
+// server and credentials out of the picture entirely.
+const snapshotReplayHost = "http://kubectl-status-snapshot.invalid"
+
+// WrapConfig replaces config with one whose only transport is the replayer; it has the shape of
+// genericclioptions.ConfigFlags.WrapConfigFn.
+func (s *SnapshotReplayer) WrapConfig(config *rest.Config) *rest.Config {
+	return &rest.Config{
+		Host:      snapshotReplayHost,
+		Transport: s,
+		QPS:       config.QPS,
+		Burst:     config.Burst,
+		UserAgent: config.UserAgent,
+	}
+}
+
+// NewFactory returns a factory of its own whose every client answers from the bundle, for reading
+// a recorded cluster side by side with the one the invocation's main factory talks to. Discovery
+// is cached under cacheDir, which should be as throwaway as the --snapshot-in one.
+func (s *SnapshotReplayer) NewFactory(cacheDir string) util.Factory {
+	configFlags := genericclioptions.NewConfigFlags(true)
+	configFlags.CacheDir = &cacheDir
+	configFlags.WrapConfigFn = s.WrapConfig
+	return util.NewFactory(configFlags)
+}
+
+// RoundTrip serves req from the bundle. A request the capture never made -- e.g. a newer
+// template looking up a related object the older one didn't -- gets a 404, which every
+// ResourceRepo caller already treats as "no such object" rather than as a failure.
+func (s *SnapshotReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := snapshotRequestKey(req)
+	entry, ok := s.responses[key]
+	if !ok {
+		klog.V(2).InfoS("request not recorded in snapshot, answering 404", "request", key)
+		status := metav1.Status{
+			TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+			Status:   metav1.StatusFailure,
+			Message:  fmt.Sprintf("%s was not recorded in the snapshot", key),
+			Reason:   metav1.StatusReasonNotFound,
+			Code:     http.StatusNotFound,
+		}
+		body, _ := json.Marshal(status)
+		entry = snapshotEntry{StatusCode: http.StatusNotFound, ContentType: "application/json", body: body}
+	}
+	header := http.Header{}
+	if entry.ContentType != "" {
+		header.Set("Content-Type", entry.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,356 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// clusterDashboardArg is the argument `kubectl status cluster` is recognised by. Only the bare
+// word on its own selects the dashboard, so Cluster API's Clusters are still reachable as
+// `clusters`, `cluster NAME` or `clusters.cluster.x-k8s.io`.
+const clusterDashboardArg = "cluster"
+
+// ClusterDashboardBoolConflicts and ClusterDashboardStringConflicts are the flags that pick
+// another mode, or shape a per-object render, and so can't be combined with the dashboard.
+var (
+	ClusterDashboardBoolConflicts   = []string{"watch", "tui", "short", "problems", "exit-code", "local"}
+	ClusterDashboardStringConflicts = []string{"output", "wait-for", "since-snapshot", "snapshot-out", "snapshot-in"}
+)
+
+// IsClusterDashboardQuery reports whether args ask for `kubectl status cluster`.
+func IsClusterDashboardQuery(args []string) bool {
+	return len(args) == 1 && args[0] == clusterDashboardArg
+}
+
+// Resource types the dashboard lists, every one of them across all namespaces.
+const (
+	clusterNodesType       = "nodes"
+	clusterAPIServicesType = "apiservices.apiregistration.k8s.io"
+	clusterPVCsType        = "persistentvolumeclaims"
+)
+
+var (
+	// clusterWorkloadTypes are the kinds whose non-Current objects the dashboard counts.
+	clusterWorkloadTypes = []string{"deployments.apps", "statefulsets.apps", "daemonsets.apps", "jobs.batch"}
+	clusterWebhookTypes  = []string{
+		"validatingwebhookconfigurations.admissionregistration.k8s.io",
+		"mutatingwebhookconfigurations.admissionregistration.k8s.io",
+	}
+	// nodePressureConditions are the Node conditions that are a problem when True.
+	nodePressureConditions = []string{"MemoryPressure", "DiskPressure", "PIDPressure", "NetworkUnavailable"}
+)
+
+// clusterDashboard is everything `kubectl status cluster` lists, and the types it couldn't.
+type clusterDashboard struct {
+	objects map[string][]RenderableObject
+	errs    []error
+}
+
+func (d clusterDashboard) list(resourceTypes ...string) (out []RenderableObject) {
+	for _, resourceType := range resourceTypes {
+		out = append(out, d.objects[resourceType]...)
+	}
+	return out
+}
+
+func loadClusterDashboard(engine *renderEngine, repo *input.ResourceRepo) clusterDashboard {
+	resourceTypes := []string{clusterNodesType, clusterAPIServicesType, clusterPVCsType}
+	resourceTypes = append(resourceTypes, clusterWorkloadTypes...)
+	resourceTypes = append(resourceTypes, clusterWebhookTypes...)
+	var loads []func()
+	for _, resourceType := range resourceTypes {
+		loads = append(loads, func() { _, _ = repo.Objects("", []string{resourceType}, "") })
+	}
+	repo.Prefetch(loads)
+	d := clusterDashboard{objects: map[string][]RenderableObject{}}
+	for _, resourceType := range resourceTypes {
+		objects, err := repo.Objects("", []string{resourceType}, "")
+		if err != nil {
+			klog.V(1).ErrorS(err, "Error listing resources for the cluster dashboard", "type", resourceType)
+			d.errs = append(d.errs, fmt.Errorf("%s: %w", resourceType, err))
+		}
+		for _, obj := range objects {
+			d.objects[resourceType] = append(d.objects[resourceType], newRenderableObject(obj, engine, repo))
+		}
+	}
+	return d
+}
+
+// nodeProblems lists what is wrong with a Node: not Ready (with the condition's reason),
+// cordoned, and any pressure condition that is True.
+func nodeProblems(node RenderableObject) (problems []string) {
+	conditions := map[string]map[string]interface{}{}
+	items, _, _ := unstructured.NestedSlice(node.Object, "status", "conditions")
+	for _, item := range items {
+		if condition, ok := item.(map[string]interface{}); ok {
+			if conditionType, ok := condition["type"].(string); ok {
+				conditions[conditionType] = condition
+			}
+		}
+	}
+	if ready := conditions["Ready"]; ready == nil || ready["status"] != "True" {
+		problem := "NotReady"
+		if reason, _ := ready["reason"].(string); reason != "" {
+			problem += ": " + reason
+		}
+		problems = append(problems, problem)
+	}
+	if unschedulable, _, _ := unstructured.NestedBool(node.Object, "spec", "unschedulable"); unschedulable {
+		problems = append(problems, "SchedulingDisabled")
+	}
+	for _, conditionType := range nodePressureConditions {
+		if condition := conditions[conditionType]; condition != nil && condition["status"] == "True" {
+			problems = append(problems, conditionType)
+		}
+	}
+	return problems
+}
+
+// apiServiceUnavailableReason returns why an APIService isn't Available, or "" when it is.
+func apiServiceUnavailableReason(apiService RenderableObject) string {
+	items, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, item := range items {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Available" {
+			continue
+		}
+		if condition["status"] == "True" {
+			return ""
+		}
+		var parts []string
+		for _, key := range []string{"reason", "message"} {
+			if value, _ := condition[key].(string); value != "" {
+				parts = append(parts, value)
+			}
+		}
+		if len(parts) == 0 {
+			return "not available"
+		}
+		return strings.Join(parts, ": ")
+	}
+	return "no Available condition reported"
+}
+
+// namespaceGroup is every object of interest in one namespace.
+type namespaceGroup struct {
+	namespace string
+	objects   []RenderableObject
+}
+
+// groupByNamespace buckets objects by namespace, sorted by namespace.
+func groupByNamespace(objects []RenderableObject) []namespaceGroup {
+	byNamespace := map[string]*namespaceGroup{}
+	for _, r := range objects {
+		group, ok := byNamespace[r.Namespace()]
+		if !ok {
+			group = &namespaceGroup{namespace: r.Namespace()}
+			byNamespace[r.Namespace()] = group
+		}
+		group.objects = append(group.objects, r)
+	}
+	groups := make([]namespaceGroup, 0, len(byNamespace))
+	for _, group := range byNamespace {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].namespace < groups[j].namespace })
+	return groups
+}
+
+// failingWebhook is one webhook whose backing Service can't answer admission requests.
+type failingWebhook struct {
+	configuration RenderableObject
+	name          string
+	failurePolicy string
+	problem       string
+}
+
+// failingWebhooks returns the webhooks of a Validating/MutatingWebhookConfiguration whose
+// Service backendProblem finds a problem with. URL-configured webhooks can't be checked from
+// here and are left out.
+func failingWebhooks(configuration RenderableObject, backendProblem func(namespace, name string) string) (out []failingWebhook) {
+	webhooks, _, _ := unstructured.NestedSlice(configuration.Object, "webhooks")
+	for _, item := range webhooks {
+		webhook, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		namespace, _, _ := unstructured.NestedString(webhook, "clientConfig", "service", "namespace")
+		service, _, _ := unstructured.NestedString(webhook, "clientConfig", "service", "name")
+		if service == "" {
+			continue
+		}
+		problem := backendProblem(namespace, service)
+		if problem == "" {
+			continue
+		}
+		name, _ := webhook["name"].(string)
+		failurePolicy, _ := webhook["failurePolicy"].(string)
+		if failurePolicy == "" {
+			failurePolicy = "Fail"
+		}
+		out = append(out, failingWebhook{configuration: configuration, name: name, failurePolicy: failurePolicy, problem: problem})
+	}
+	return out
+}
+
+// webhookServiceProblem is failingWebhooks' backendProblem against the cluster: the Service
+// must exist and have at least one ready endpoint, like the webhook configuration templates check.
+func webhookServiceProblem(repo *input.ResourceRepo) func(namespace, name string) string {
+	return func(namespace, name string) string {
+		if _, err := repo.Service(namespace, name); apierrors.IsNotFound(err) {
+			return fmt.Sprintf("Service %s/%s not found", namespace, name)
+		} else if err != nil {
+			klog.V(3).ErrorS(err, "error getting webhook service", "namespace", namespace, "name", name)
+			return ""
+		}
+		slices, err := repo.Objects(namespace, []string{"endpointslices"}, discoveryv1.LabelServiceName+"="+name)
+		if err != nil {
+			klog.V(3).ErrorS(err, "error listing webhook service endpointslices", "namespace", namespace, "name", name)
+			return ""
+		}
+		for _, slice := range slices {
+			endpoints, _, _ := unstructured.NestedSlice(slice, "endpoints")
+			for _, item := range endpoints {
+				endpoint, _ := item.(map[string]interface{})
+				if ready, found, _ := unstructured.NestedBool(endpoint, "conditions", "ready"); !found || ready {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("Service %s/%s has no ready endpoints", namespace, name)
+	}
+}
+
+// clusterDashboardWriter prints the dashboard's sections, a heading each, the way runProblems
+// prints its kind groups.
+type clusterDashboardWriter struct {
+	out     io.Writer
+	started bool
+}
+
+func (w *clusterDashboardWriter) heading(format string, a ...interface{}) {
+	if w.started {
+		_, _ = fmt.Fprintln(w.out)
+	}
+	w.started = true
+	_, _ = color.New(color.Bold, color.Underline).Fprintf(w.out, format, a...)
+	_, _ = fmt.Fprintln(w.out)
+}
+
+func (w *clusterDashboardWriter) line(indent int, text string) {
+	_, _ = fmt.Fprintf(w.out, "%s%s\n", strings.Repeat("  ", indent), text)
+}
+
+func (w *clusterDashboardWriter) summary(indent int, r RenderableObject) {
+	summary, err := r.HealthSummary("")
+	if err != nil {
+		summary = fmt.Sprintf("%s: failed to render: %s", r.String(), err)
+	}
+	w.line(indent, summary)
+}
+
+// runClusterDashboard is `kubectl status cluster`: a one-screen overview of a whole cluster for
+// the start of an incident -- Node readiness and pressure, unavailable APIServices (the
+// aggregated APIs behind metrics, custom metrics and the like), non-Current workloads per
+// namespace, Pending PVCs, and admission webhooks whose Service can't answer. Like runProblems,
+// a type that can't be listed is reported after the rest instead of failing the whole view.
+func runClusterDashboard(engine *renderEngine, repo *input.ResourceRepo) error {
+	d := loadClusterDashboard(engine, repo)
+	w := &clusterDashboardWriter{out: engine.ioStreams.Out}
+
+	nodes := d.list(clusterNodesType)
+	var nodeLines []string
+	ready := 0
+	for _, node := range nodes {
+		problems := nodeProblems(node)
+		if len(problems) == 0 || !strings.HasPrefix(problems[0], "NotReady") {
+			ready++
+		}
+		if len(problems) > 0 {
+			nodeLines = append(nodeLines, fmt.Sprintf("%s %s", node.String(), color.RedString(strings.Join(problems, ", "))))
+		}
+	}
+	w.heading("Nodes (%d/%d Ready)", ready, len(nodes))
+	for _, line := range nodeLines {
+		w.line(1, line)
+	}
+	if len(nodeLines) == 0 && len(nodes) > 0 {
+		w.line(1, color.GreenString("all Ready, no pressure"))
+	}
+
+	apiServices := d.list(clusterAPIServicesType)
+	var unavailable []string
+	for _, apiService := range apiServices {
+		if reason := apiServiceUnavailableReason(apiService); reason != "" {
+			unavailable = append(unavailable, fmt.Sprintf("%s %s", apiService.String(), color.RedString(reason)))
+		}
+	}
+	w.heading("APIServices (%d/%d Available)", len(apiServices)-len(unavailable), len(apiServices))
+	for _, line := range unavailable {
+		w.line(1, line)
+	}
+	if len(unavailable) == 0 && len(apiServices) > 0 {
+		w.line(1, color.GreenString("all Available"))
+	}
+
+	var problematic []RenderableObject
+	workloads := d.list(clusterWorkloadTypes...)
+	for _, r := range workloads {
+		if r.Problematic() {
+			problematic = append(problematic, r)
+		}
+	}
+	groups := groupByNamespace(problematic)
+	w.heading("Workloads not Current (%d of %d, in %d namespaces)", len(problematic), len(workloads), len(groups))
+	for _, group := range groups {
+		w.line(1, fmt.Sprintf("%s (%d)", color.New(color.Bold).Sprint(group.namespace), len(group.objects)))
+		for _, r := range group.objects {
+			w.summary(2, r)
+		}
+	}
+
+	var pending []RenderableObject
+	for _, pvc := range d.list(clusterPVCsType) {
+		if phase, _, _ := unstructured.NestedString(pvc.Object, "status", "phase"); phase == "Pending" {
+			pending = append(pending, pvc)
+		}
+	}
+	w.heading("Pending PersistentVolumeClaims (%d)", len(pending))
+	for _, pvc := range pending {
+		w.summary(1, pvc)
+	}
+
+	configurations := d.list(clusterWebhookTypes...)
+	var failing []failingWebhook
+	for _, configuration := range configurations {
+		failing = append(failing, failingWebhooks(configuration, webhookServiceProblem(repo))...)
+	}
+	w.heading("Failing admission webhooks (%d)", len(failing))
+	for _, webhook := range failing {
+		policy := webhook.failurePolicy
+		if policy == "Fail" {
+			// With failurePolicy Fail, every request the webhook matches is rejected.
+			policy = color.New(color.FgRed, color.Bold).Sprint(policy)
+		}
+		w.line(1, fmt.Sprintf("%s webhook %s (failurePolicy %s): %s",
+			webhook.configuration.String(), webhook.name, policy, color.RedString(webhook.problem)))
+	}
+
+	if len(d.errs) > 0 {
+		_, _ = fmt.Fprintln(engine.ioStreams.ErrOut)
+		for _, err := range d.errs {
+			errorPrintf(engine.ioStreams.ErrOut, "Could not check %s", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNode(unschedulable bool, conditions ...map[string]interface{}) RenderableObject {
+	var items []interface{}
+	for _, condition := range conditions {
+		items = append(items, condition)
+	}
+	return newTestRenderableObject(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Node",
+		"metadata":   map[string]interface{}{"name": "node-1"},
+		"spec":       map[string]interface{}{"unschedulable": unschedulable},
+		"status":     map[string]interface{}{"conditions": items},
+	})
+}
+
+func TestNodeProblems(t *testing.T) {
+	ready := map[string]interface{}{"type": "Ready", "status": "True"}
+	tests := []struct {
+		name string
+		node RenderableObject
+		want []string
+	}{
+		{name: "healthy", node: testNode(false, ready, map[string]interface{}{"type": "MemoryPressure", "status": "False"})},
+		{
+			name: "not ready",
+			node: testNode(false, map[string]interface{}{"type": "Ready", "status": "False", "reason": "KubeletNotReady"}),
+			want: []string{"NotReady: KubeletNotReady"},
+		},
+		{name: "no conditions yet", node: testNode(false), want: []string{"NotReady"}},
+		{
+			name: "cordoned under pressure",
+			node: testNode(true, ready, map[string]interface{}{"type": "DiskPressure", "status": "True"}),
+			want: []string{"SchedulingDisabled", "DiskPressure"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nodeProblems(tt.node))
+		})
+	}
+}
+
+func TestAPIServiceUnavailableReason(t *testing.T) {
+	apiService := func(conditions ...interface{}) RenderableObject {
+		return newTestRenderableObject(map[string]interface{}{
+			"kind":   "APIService",
+			"status": map[string]interface{}{"conditions": conditions},
+		})
+	}
+	assert.Equal(t, "", apiServiceUnavailableReason(apiService(map[string]interface{}{"type": "Available", "status": "True"})))
+	assert.Equal(t, "FailedDiscoveryCheck: failing or missing response",
+		apiServiceUnavailableReason(apiService(map[string]interface{}{
+			"type": "Available", "status": "False", "reason": "FailedDiscoveryCheck", "message": "failing or missing response",
+		})))
+	assert.Equal(t, "no Available condition reported", apiServiceUnavailableReason(apiService()))
+}
+
+func TestFailingWebhooks(t *testing.T) {
+	configuration := newTestRenderableObject(map[string]interface{}{
+		"kind":     "ValidatingWebhookConfiguration",
+		"metadata": map[string]interface{}{"name": "policy"},
+		"webhooks": []interface{}{
+			map[string]interface{}{"name": "up.example.com", "clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{"namespace": "policy", "name": "up"},
+			}},
+			map[string]interface{}{"name": "down.example.com", "failurePolicy": "Ignore", "clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{"namespace": "policy", "name": "down"},
+			}},
+			map[string]interface{}{"name": "external.example.com", "clientConfig": map[string]interface{}{
+				"url": "https://example.com/validate",
+			}},
+		},
+	})
+	failing := failingWebhooks(configuration, func(namespace, name string) string {
+		if name == "down" {
+			return "Service policy/down has no ready endpoints"
+		}
+		return ""
+	})
+	require.Len(t, failing, 1)
+	assert.Equal(t, "down.example.com", failing[0].name)
+	assert.Equal(t, "Ignore", failing[0].failurePolicy)
+	assert.Equal(t, "Service policy/down has no ready endpoints", failing[0].problem)
+}
+
+func TestGroupByNamespace(t *testing.T) {
+	groups := groupByNamespace([]RenderableObject{
+		testWatchedObject("Deployment", "web", "a", "1"),
+		testWatchedObject("Deployment", "api", "b", "2"),
+		testWatchedObject("StatefulSet", "web", "c", "3"),
+	})
+	require.Len(t, groups, 2)
+	assert.Equal(t, "api", groups[0].namespace)
+	assert.Equal(t, "web", groups[1].namespace)
+	assert.Len(t, groups[1].objects, 2)
+}
+
+func TestIsClusterDashboardQuery(t *testing.T) {
+	assert.True(t, IsClusterDashboardQuery([]string{"cluster"}))
+	assert.False(t, IsClusterDashboardQuery([]string{"clusters"}))
+	assert.False(t, IsClusterDashboardQuery([]string{"cluster", "prod"}))
+	assert.False(t, IsClusterDashboardQuery(nil))
+}
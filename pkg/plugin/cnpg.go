@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cnpgStandbys reads the replicationInfo a CloudNativePG primary's instance manager reports -- its
+// pg_stat_replication rows -- into one dict per standby, keyed by application_name, which
+// CloudNativePG sets to the standby's Pod name. A standby missing from the result isn't streaming
+// from the primary at all.
+//
+// Each dict has "state" (streaming, catchup, ...), "syncState" (sync, quorum, potential, async),
+// "replayLag" (the time-based lag PostgreSQL measured, empty when idle) and "lagBytes": how much
+// WAL the primary has written past what the standby has replayed, -1 when an LSN is missing.
+func cnpgStandbys(primaryStatus map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	currentLSN, currentOK := parseLSN(primaryStatus["currentLsn"])
+	replicationInfo, _ := primaryStatus["replicationInfo"].([]interface{})
+	for _, row := range toInterfaceMapSlice(replicationInfo) {
+		name, _ := row["applicationName"].(string)
+		if name == "" {
+			continue
+		}
+		lagBytes := int64(-1)
+		if replayLSN, ok := parseLSN(row["replayLsn"]); ok && currentOK && currentLSN >= replayLSN {
+			lagBytes = int64(currentLSN - replayLSN)
+		}
+		state, _ := row["state"].(string)
+		syncState, _ := row["syncState"].(string)
+		result[name] = map[string]interface{}{
+			"state":     state,
+			"syncState": syncState,
+			"replayLag": cnpgLag(row["replayLag"]),
+			"lagBytes":  lagBytes,
+		}
+	}
+	return result
+}
+
+// parseLSN parses a PostgreSQL log sequence number, printed as two hex halves "16/B374D848".
+func parseLSN(v interface{}) (uint64, bool) {
+	s, _ := v.(string)
+	hi, lo, found := strings.Cut(s, "/")
+	if !found {
+		return 0, false
+	}
+	high, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	low, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return high<<32 | low, true
+}
+
+// cnpgLag prints one of pg_stat_replication's *_lag columns as the instance manager reports it: a
+// Go duration in nanoseconds, or already a string. PostgreSQL leaves the columns empty while the
+// standby is fully caught up and the primary is idle, which prints as "".
+func cnpgLag(v interface{}) string {
+	switch lag := v.(type) {
+	case float64:
+		return time.Duration(lag).Round(time.Millisecond).String()
+	case int64:
+		return time.Duration(lag).Round(time.Millisecond).String()
+	case string:
+		return lag
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(lag)
+	}
+}
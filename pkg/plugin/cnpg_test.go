@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCnpgStandbys(t *testing.T) {
+	primary := map[string]interface{}{
+		"currentLsn": "1/0000A000",
+		"replicationInfo": []interface{}{
+			map[string]interface{}{"applicationName": "pg-2", "state": "streaming", "syncState": "quorum",
+				"replayLsn": "1/00008000", "replayLag": float64(1500000)},
+			map[string]interface{}{"applicationName": "pg-3", "state": "catchup", "syncState": "async",
+				"replayLsn": "0/FFFFF000"},
+			map[string]interface{}{"applicationName": "pg-4", "state": "streaming", "syncState": "async"},
+			map[string]interface{}{"state": "streaming"},
+		},
+	}
+	want := map[string]interface{}{
+		"pg-2": map[string]interface{}{"state": "streaming", "syncState": "quorum", "replayLag": "2ms", "lagBytes": int64(0x2000)},
+		"pg-3": map[string]interface{}{"state": "catchup", "syncState": "async", "replayLag": "", "lagBytes": int64(0xB000)},
+		"pg-4": map[string]interface{}{"state": "streaming", "syncState": "async", "replayLag": "", "lagBytes": int64(-1)},
+	}
+	if got := cnpgStandbys(primary); !reflect.DeepEqual(got, want) {
+		t.Errorf("cnpgStandbys() = %v, want %v", got, want)
+	}
+	if got := cnpgStandbys(map[string]interface{}{}); len(got) != 0 {
+		t.Errorf("cnpgStandbys() of a status without replicationInfo = %v, want empty", got)
+	}
+}
+
+func TestParseLSN(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		want   uint64
+		wantOK bool
+	}{
+		{"16/B374D848", 0x16B374D848, true},
+		{"0/0", 0, true},
+		{"B374D848", 0, false},
+		{"x/1", 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseLSN(tt.in); got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseLSN(%v) = %x, %v, want %x, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -119,7 +119,7 @@ func colorKeyword(phase string) string {
 		return color.GreenString(phase)
 	case "Pending", "Released", "Burstable", "Active", "InProgress", "superseded", "pending-install", "pending-upgrade", "pending-rollback", "uninstalling":
 		return color.YellowString(phase)
-	case "Failed", "Unknown", "Terminating", "Evicted", "BestEffort", "OOMKilled", "ContainerCannotRun", "Error", "NotFound", "failed", "unknown":
+	case "Failed", "Unknown", "Terminating", "Evicted", "BestEffort", "OOMKilled", "ContainerCannotRun", "Error", "NotFound", "failed", "unknown", "PartiallyFailed", "FailedValidation":
 		return color.New(color.FgRed, color.Bold).Sprintf("%s", phase)
 	default:
 		return phase
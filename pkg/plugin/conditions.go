@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 )
 
@@ -103,6 +104,8 @@ func isStatusConditionHealthy(condition map[string]interface{}) bool {
 		strings.HasSuffix(fmt.Sprint(condition["type"]), "Failed"), // Failed Jobs has this condition
 		strings.HasSuffix(fmt.Sprint(condition["type"]), "Warning"),
 		strings.HasPrefix(fmt.Sprint(condition["type"]), "Corrupt"),
+		condition["type"] == "ErrorOccurred", // Argo CD ApplicationSet
+		condition["type"] == "Fallback",      // KEDA ScaledObject serving its fallback replica count
 
 		// Conditions from "Node Problem Detector"
 		condition["type"] == "DockerContainerStartupFailure",
@@ -148,3 +151,57 @@ func isStatusConditionHealthy(condition map[string]interface{}) bool {
 		}
 	}
 }
+
+// StatusConditionHealthy is isStatusConditionHealthy for one of r's own conditions, for the
+// condition types whose polarity depends on the object rather than on the type alone. A Paused
+// condition is only a problem when nothing in the object asked for the pause: a KEDA scaler
+// paused by its annotation or a Rollout held at a canary pause step is doing what it was told.
+func (r RenderableObject) StatusConditionHealthy(condition map[string]interface{}) bool {
+	if condition["type"] == "Paused" {
+		if intended, ok := r.pauseIntended(); ok {
+			switch condition["status"] {
+			case "False":
+				return true
+			case "True":
+				return intended
+			default:
+				return false
+			}
+		}
+	}
+	return isStatusConditionHealthy(condition)
+}
+
+// pauseIntended reports whether r's spec or annotations ask for the pause its Paused condition
+// reports. ok is false for kinds whose Paused condition kubectl-status has no spec to check
+// against.
+func (r RenderableObject) pauseIntended() (intended, ok bool) {
+	switch {
+	case strings.HasPrefix(r.APIVersion(), "keda.sh/") && (r.Kind() == "ScaledObject" || r.Kind() == "ScaledJob"):
+		// The same two annotations the keda_paused template reports; the scale-in/scale-out
+		// variants only stop one direction and don't set Paused.
+		annotations := r.GetAnnotations()
+		_, pausedReplicas := annotations["autoscaling.keda.sh/paused-replicas"]
+		return pausedReplicas || annotations["autoscaling.keda.sh/paused"] == "true", true
+	case strings.HasPrefix(r.APIVersion(), "argoproj.io/") && r.Kind() == "Rollout":
+		if paused, _, _ := unstructured.NestedBool(r.Object, "spec", "paused"); paused {
+			return true, true
+		}
+		// A canary pause step or a blue-green pre-promotion pause is part of the strategy; any
+		// other pause reason, e.g. InconclusiveAnalysis, is the controller stopping on its own.
+		pauseConditions, _, _ := unstructured.NestedSlice(r.Object, "status", "pauseConditions")
+		if len(pauseConditions) == 0 {
+			return false, true
+		}
+		for _, c := range pauseConditions {
+			pauseCondition, _ := c.(map[string]interface{})
+			switch pauseCondition["reason"] {
+			case "CanaryPauseStep", "BlueGreenPause":
+			default:
+				return false, true
+			}
+		}
+		return true, true
+	}
+	return false, false
+}
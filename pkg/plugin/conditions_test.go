@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestIsStatusConditionHealthyUserProvidedTypes(t *testing.T) {
@@ -73,3 +75,89 @@ func TestIsStatusConditionHealthyUserProvidedTypes(t *testing.T) {
 		}
 	})
 }
+
+func TestStatusConditionHealthyPaused(t *testing.T) {
+	object := func(apiVersion, kind string, annotations, spec, status map[string]interface{}) RenderableObject {
+		return RenderableObject{Unstructured: unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "x", "namespace": "test", "annotations": annotations},
+			"spec":       spec,
+			"status":     status,
+		}}}
+	}
+	pausedTrue := map[string]interface{}{"type": "Paused", "status": "True"}
+	pausedFalse := map[string]interface{}{"type": "Paused", "status": "False"}
+	tests := []struct {
+		name      string
+		obj       RenderableObject
+		condition map[string]interface{}
+		want      bool
+	}{
+		{
+			name:      "ScaledObject paused by its annotation",
+			obj:       object("keda.sh/v1alpha1", "ScaledObject", map[string]interface{}{"autoscaling.keda.sh/paused": "true"}, nil, nil),
+			condition: pausedTrue,
+			want:      true,
+		},
+		{
+			name:      "ScaledJob held at paused-replicas",
+			obj:       object("keda.sh/v1alpha1", "ScaledJob", map[string]interface{}{"autoscaling.keda.sh/paused-replicas": "0"}, nil, nil),
+			condition: pausedTrue,
+			want:      true,
+		},
+		{
+			name:      "ScaledObject paused without an annotation asking for it",
+			obj:       object("keda.sh/v1alpha1", "ScaledObject", nil, nil, nil),
+			condition: pausedTrue,
+			want:      false,
+		},
+		{
+			name:      "ScaledObject not paused",
+			obj:       object("keda.sh/v1alpha1", "ScaledObject", nil, nil, nil),
+			condition: pausedFalse,
+			want:      true,
+		},
+		{
+			name:      "Rollout paused manually",
+			obj:       object("argoproj.io/v1alpha1", "Rollout", nil, map[string]interface{}{"paused": true}, nil),
+			condition: pausedTrue,
+			want:      true,
+		},
+		{
+			name: "Rollout at a canary pause step",
+			obj: object("argoproj.io/v1alpha1", "Rollout", nil, nil, map[string]interface{}{
+				"pauseConditions": []interface{}{map[string]interface{}{"reason": "CanaryPauseStep"}},
+			}),
+			condition: pausedTrue,
+			want:      true,
+		},
+		{
+			name: "Rollout paused on an inconclusive analysis",
+			obj: object("argoproj.io/v1alpha1", "Rollout", nil, nil, map[string]interface{}{
+				"pauseConditions": []interface{}{map[string]interface{}{"reason": "InconclusiveAnalysis"}},
+			}),
+			condition: pausedTrue,
+			want:      false,
+		},
+		{
+			name:      "Rollout resumed",
+			obj:       object("argoproj.io/v1alpha1", "Rollout", nil, nil, nil),
+			condition: pausedFalse,
+			want:      true,
+		},
+		{
+			name:      "other kinds keep the default polarity",
+			obj:       object("example.com/v1", "Widget", nil, nil, nil),
+			condition: pausedTrue,
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.obj.StatusConditionHealthy(tt.condition); got != tt.want {
+				t.Errorf("StatusConditionHealthy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
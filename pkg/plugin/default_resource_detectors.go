@@ -90,9 +90,35 @@ func detectCrossplaneResource(obj RenderableObject) (string, bool) {
 		return "crossplane_default_resource", true
 	}
 
+	if isCrossplaneClaim(spec) {
+		return "crossplane_default_resource", true
+	}
+
 	return "", false
 }
 
+// isCrossplaneClaim recognizes a v1 claim that carries no compositionRef yet -- one Crossplane
+// hasn't bound to a composite, or one bound before the compositionRef was copied back. A claim
+// names its composite in spec.resourceRef as a full {apiVersion, kind, name} reference, which on
+// its own is too common a field name to go by; the claim CRD Crossplane generates always
+// defaults spec.compositeDeletePolicy, which no other object has, so the two together are.
+func isCrossplaneClaim(spec map[string]interface{}) bool {
+	if _, ok := spec["compositeDeletePolicy"].(string); !ok {
+		return false
+	}
+	ref, ok := spec["resourceRef"].(map[string]interface{})
+	if !ok {
+		// Not bound yet: compositeDeletePolicy alone is the whole signal.
+		return true
+	}
+	for _, field := range []string{"apiVersion", "kind", "name"} {
+		if _, ok := ref[field].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // hasNamedRef mirrors `and (kindIs "map" $ref) (kindIs "string" $ref.name)`: a map with a "name"
 // key whose value is a string (an empty string still counts -- this only checks shape, not that
 // the reference is non-empty, matching the template condition it replaces).
@@ -119,6 +119,32 @@ func TestDetectCrossplaneResource(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "v1 claim bound to its composite",
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"compositeDeletePolicy": "Background",
+					"resourceRef":           map[string]interface{}{"apiVersion": "db.example.org/v1alpha1", "kind": "XPostgreSQLInstance", "name": "orders-db-x7k2p"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "v1 claim not bound yet",
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{"compositeDeletePolicy": "Foreground"},
+			},
+			want: true,
+		},
+		{
+			name: "resourceRef without compositeDeletePolicy (coincidental field name)",
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"resourceRef": map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "name": "x"},
+				},
+			},
+			want: false,
+		},
 		{
 			name: "no spec at all",
 			obj:  map[string]interface{}{},
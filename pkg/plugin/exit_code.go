@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// Process exit codes reported under --exit-code. 1 stays reserved for every other error (bad
+// flags, unreachable API server, ...), which is what any kubectl command exits with, so a CI job
+// can tell "the rollout is broken" apart from "the check itself couldn't run".
+const (
+	ExitCodeHealthy    = 0
+	ExitCodeInProgress = 2
+	ExitCodeFailed     = 3
+	ExitCodeNotFound   = 4
+)
+
+// HealthExitError is what Run returns under --exit-code when not every visited object is
+// Current. Code is the process exit code the caller should use; see the ExitCode* constants.
+type HealthExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *HealthExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HealthExitError) Unwrap() error {
+	return e.Err
+}
+
+// healthTally accumulates the kstatus verdict of every object a run visited, plus the requested
+// objects that didn't exist, into the single worst exit code for --exit-code.
+type healthTally struct {
+	total    int
+	notFound int
+	byStatus map[kstatus.Status]int
+}
+
+func newHealthTally() *healthTally {
+	return &healthTally{byStatus: map[kstatus.Status]int{}}
+}
+
+// add records r's kstatus. An object kstatus can't compute a result for counts as Unknown,
+// which, like Terminating, is reported as in progress: neither is a verdict a pipeline should
+// pass on, and neither is a definite failure either.
+func (t *healthTally) add(r RenderableObject) {
+	t.total++
+	status := kstatus.UnknownStatus
+	if result := r.KStatus(); result != nil {
+		status = result.Status
+	}
+	t.byStatus[status]++
+}
+
+// addVisitError records the not-found errors in err -- a `deploy/x` that doesn't exist comes
+// back from the resource builder as an error, not as an object -- and returns whatever else err
+// carried, which the caller should still fail on as before.
+func (t *healthTally) addVisitError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var rest []error
+	for _, e := range flattenVisitError(err) {
+		if apierrors.IsNotFound(e) {
+			t.total++
+			t.notFound++
+			continue
+		}
+		rest = append(rest, e)
+	}
+	return utilerrors.NewAggregate(rest)
+}
+
+func flattenVisitError(err error) []error {
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		return utilerrors.Flatten(agg).Errors()
+	}
+	return []error{err}
+}
+
+// exitError returns nil when everything visited is Current, otherwise a HealthExitError with
+// the worst code seen: Failed outranks a missing object, which outranks a rollout that is merely
+// still in progress -- waiting longer can fix the last one, never the first two.
+func (t *healthTally) exitError() error {
+	failed := t.byStatus[kstatus.FailedStatus]
+	inProgress := t.byStatus[kstatus.InProgressStatus] + t.byStatus[kstatus.TerminatingStatus] + t.byStatus[kstatus.UnknownStatus]
+	notFound := t.notFound + t.byStatus[kstatus.NotFoundStatus]
+	switch {
+	case t.total == 0:
+		return &HealthExitError{Code: ExitCodeNotFound, Err: fmt.Errorf("no resources found")}
+	case failed > 0:
+		return &HealthExitError{Code: ExitCodeFailed, Err: t.summary()}
+	case notFound > 0:
+		return &HealthExitError{Code: ExitCodeNotFound, Err: t.summary()}
+	case inProgress > 0:
+		return &HealthExitError{Code: ExitCodeInProgress, Err: t.summary()}
+	}
+	return nil
+}
+
+// summary is the one-line stderr explanation accompanying a non-zero exit, e.g.
+// "2 of 5 resources are not Current: 1 Failed, 1 InProgress".
+func (t *healthTally) summary() error {
+	var parts []string
+	for _, status := range []kstatus.Status{kstatus.FailedStatus, kstatus.NotFoundStatus, kstatus.InProgressStatus, kstatus.TerminatingStatus, kstatus.UnknownStatus} {
+		count := t.byStatus[status]
+		if status == kstatus.NotFoundStatus {
+			count += t.notFound
+		}
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, status))
+		}
+	}
+	notCurrent := t.total - t.byStatus[kstatus.CurrentStatus]
+	return fmt.Errorf("%d of %d resources are not Current: %s", notCurrent, t.total, strings.Join(parts, ", "))
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func testPod(phase string) RenderableObject {
+	return newTestRenderableObject(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "p", "namespace": "ns"},
+		"status":     map[string]interface{}{"phase": phase},
+	})
+}
+
+func testFailedJob() RenderableObject {
+	return newTestRenderableObject(map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "j", "namespace": "ns"},
+		"spec":       map[string]interface{}{},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"},
+			},
+		},
+	})
+}
+
+func TestHealthTallyExitError(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "x")
+	tests := []struct {
+		name      string
+		objects   []RenderableObject
+		visitErr  error
+		wantCode  int
+		wantRest  bool
+		wantError string
+	}{
+		{
+			name:     "all current",
+			objects:  []RenderableObject{testPod("Succeeded")},
+			wantCode: ExitCodeHealthy,
+		},
+		{
+			name:      "in progress",
+			objects:   []RenderableObject{testPod("Succeeded"), testPod("Pending")},
+			wantCode:  ExitCodeInProgress,
+			wantError: "1 of 2 resources are not Current: 1 InProgress",
+		},
+		{
+			name:      "failed outranks not found and in progress",
+			objects:   []RenderableObject{testFailedJob(), testPod("Pending")},
+			visitErr:  utilerrors.NewAggregate([]error{notFound}),
+			wantCode:  ExitCodeFailed,
+			wantError: "3 of 3 resources are not Current: 1 Failed, 1 NotFound, 1 InProgress",
+		},
+		{
+			name:      "not found outranks in progress",
+			objects:   []RenderableObject{testPod("Pending")},
+			visitErr:  notFound,
+			wantCode:  ExitCodeNotFound,
+			wantError: "2 of 2 resources are not Current: 1 NotFound, 1 InProgress",
+		},
+		{
+			name:      "nothing matched",
+			wantCode:  ExitCodeNotFound,
+			wantError: "no resources found",
+		},
+		{
+			name:     "other visit errors are passed through",
+			objects:  []RenderableObject{testPod("Succeeded")},
+			visitErr: utilerrors.NewAggregate([]error{notFound, fmt.Errorf("forbidden")}),
+			wantCode: ExitCodeNotFound,
+			wantRest: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := newHealthTally()
+			for _, r := range tt.objects {
+				tally.add(r)
+			}
+			rest := tally.addVisitError(tt.visitErr)
+			if tt.wantRest {
+				require.Error(t, rest)
+				assert.NotContains(t, rest.Error(), "not found")
+			} else {
+				require.NoError(t, rest)
+			}
+			err := tally.exitError()
+			if tt.wantCode == ExitCodeHealthy {
+				assert.NoError(t, err)
+				return
+			}
+			var healthErr *HealthExitError
+			require.True(t, errors.As(err, &healthErr))
+			assert.Equal(t, tt.wantCode, healthErr.Code)
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+			}
+		})
+	}
+}
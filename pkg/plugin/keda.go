@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// kedaTriggerStates pairs each of a KEDA ScaledObject's spec.triggers with what is known about it
+// at runtime. KEDA records no per-trigger state of its own beyond status.health, so the rest comes
+// from the HorizontalPodAutoscaler it generated: trigger N is exposed to that HPA as the external
+// metric "sN-<metric>" (cpu and memory triggers as the plain Resource metric of that name), and its
+// current value there, read back against the trigger's activation threshold, is whether that
+// trigger is what keeps the workload scaled up.
+//
+// Each returned dict has "type", "name" (the trigger's own optional name), "metricName" (empty when
+// the HPA doesn't list one), "health" and "failures" (from status.health), "current" and "target"
+// (the HPA's quantities, as strings), and "active" with "activityKnown". Activity is only known for
+// external metrics the HPA has a current value for: an HPA KEDA scaled to zero has none, and a
+// Resource metric has no activation threshold -- KEDA can't scale to zero on cpu or memory alone.
+func kedaTriggerStates(triggers []interface{}, health map[string]interface{}, hpa map[string]interface{}) []map[string]interface{} {
+	currentReplicas, _, _ := unstructured.NestedInt64(hpa, "status", "currentReplicas")
+	currentMetrics, _, _ := unstructured.NestedSlice(hpa, "status", "currentMetrics")
+	specMetrics, _, _ := unstructured.NestedSlice(hpa, "spec", "metrics")
+
+	var result []map[string]interface{}
+	for i, trigger := range toInterfaceMapSlice(triggers) {
+		triggerType, _ := trigger["type"].(string)
+		name, _ := trigger["name"].(string)
+		state := map[string]interface{}{
+			"type": triggerType, "name": name, "metricName": "", "health": "", "failures": int64(0),
+			"current": "", "target": "", "active": false, "activityKnown": false,
+		}
+		result = append(result, state)
+
+		metricType, metricName := "External", ""
+		if triggerType == "cpu" || triggerType == "memory" {
+			metricType, metricName = "Resource", triggerType
+		} else {
+			prefix := fmt.Sprintf("s%d-", i)
+			for _, m := range toInterfaceMapSlice(specMetrics) {
+				if n, _, _ := unstructured.NestedString(m, "external", "metric", "name"); strings.HasPrefix(n, prefix) {
+					metricName = n
+					break
+				}
+			}
+			if metricName == "" {
+				for n := range health {
+					if strings.HasPrefix(n, prefix) {
+						metricName = n
+						break
+					}
+				}
+			}
+		}
+		if metricName == "" {
+			continue
+		}
+		state["metricName"] = metricName
+		if h, ok := health[metricName].(map[string]interface{}); ok {
+			state["health"], _ = h["status"].(string)
+			state["failures"], _, _ = unstructured.NestedInt64(h, "numberOfFailures")
+		}
+
+		field := strings.ToLower(metricType)
+		for _, m := range toInterfaceMapSlice(specMetrics) {
+			if n, _, _ := unstructured.NestedString(m, kedaMetricNamePath(field)...); m["type"] == metricType && n == metricName {
+				state["target"] = kedaMetricValue(m, field, "target")
+			}
+		}
+		for _, m := range toInterfaceMapSlice(currentMetrics) {
+			n, _, _ := unstructured.NestedString(m, kedaMetricNamePath(field)...)
+			if m["type"] != metricType || n != metricName {
+				continue
+			}
+			state["current"] = kedaMetricValue(m, field, "current")
+			if metricType != "External" {
+				break
+			}
+			total, ok := kedaExternalMetricTotal(m, currentReplicas)
+			if !ok {
+				break
+			}
+			state["active"] = total > kedaActivationThreshold(trigger)
+			state["activityKnown"] = true
+			break
+		}
+	}
+	return result
+}
+
+// kedaMetricNamePath is where an HPA metric source keeps its name: "resource" has it directly,
+// "external" under metric.
+func kedaMetricNamePath(field string) []string {
+	if field == "resource" {
+		return []string{field, "name"}
+	}
+	return []string{field, "metric", "name"}
+}
+
+// kedaMetricValue prints the "current" or "target" half of an HPA metric the way the HPA template
+// does: utilization as a percentage, otherwise the average or absolute quantity.
+func kedaMetricValue(metric map[string]interface{}, field, half string) string {
+	values, _, _ := unstructured.NestedMap(metric, field, half)
+	if v, ok := values["averageUtilization"]; ok {
+		return fmt.Sprintf("%v%%", v)
+	}
+	if v, ok := values["averageValue"]; ok {
+		return fmt.Sprint(v)
+	}
+	if v, ok := values["value"]; ok {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// kedaExternalMetricTotal undoes the HPA's per-Pod averaging of an AverageValue external metric --
+// KEDA's default metricType -- to get back the value the scaler reported, which is what its
+// activation threshold is compared to.
+func kedaExternalMetricTotal(metric map[string]interface{}, currentReplicas int64) (float64, bool) {
+	if v, found, _ := unstructured.NestedFieldNoCopy(metric, "external", "current", "value"); found {
+		q, err := resource.ParseQuantity(fmt.Sprint(v))
+		return q.AsApproximateFloat64(), err == nil
+	}
+	if v, found, _ := unstructured.NestedFieldNoCopy(metric, "external", "current", "averageValue"); found {
+		q, err := resource.ParseQuantity(fmt.Sprint(v))
+		return q.AsApproximateFloat64() * float64(currentReplicas), err == nil
+	}
+	return 0, false
+}
+
+// kedaActivationThreshold is a trigger's activation threshold, 0 when it sets none. Each scaler
+// names its own (activationThreshold, activationQueueLength, activationLagThreshold, ...), but all
+// of them start with "activation"; the first that parses as a number, in key order, wins.
+func kedaActivationThreshold(trigger map[string]interface{}) float64 {
+	metadata, _ := trigger["metadata"].(map[string]interface{})
+	var keys []string
+	for k := range metadata {
+		if strings.HasPrefix(k, "activation") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if f, err := strconv.ParseFloat(fmt.Sprint(metadata[k]), 64); err == nil {
+			return f
+		}
+	}
+	return 0
+}
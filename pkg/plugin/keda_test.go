@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"testing"
+)
+
+func TestKedaTriggerStates(t *testing.T) {
+	external := func(name, half, averageValue string) map[string]interface{} {
+		return map[string]interface{}{
+			"type": "External",
+			"external": map[string]interface{}{
+				"metric": map[string]interface{}{"name": name},
+				half:     map[string]interface{}{"averageValue": averageValue},
+			},
+		}
+	}
+	triggers := []interface{}{
+		map[string]interface{}{"type": "rabbitmq", "name": "orders", "metadata": map[string]interface{}{"value": "20", "activationValue": "50"}},
+		map[string]interface{}{"type": "prometheus", "metadata": map[string]interface{}{"threshold": "100"}},
+		map[string]interface{}{"type": "cpu", "metadata": map[string]interface{}{"value": "70"}},
+		map[string]interface{}{"type": "kafka"},
+	}
+	health := map[string]interface{}{
+		"s1-prometheus": map[string]interface{}{"status": "Failing", "numberOfFailures": int64(4)},
+	}
+	hpa := map[string]interface{}{
+		"spec": map[string]interface{}{"metrics": []interface{}{
+			external("s0-rabbitmq-orders", "target", "20"),
+			external("s1-prometheus", "target", "100"),
+			map[string]interface{}{"type": "Resource", "resource": map[string]interface{}{
+				"name": "cpu", "target": map[string]interface{}{"type": "Utilization", "averageUtilization": int64(70)}}},
+		}},
+		"status": map[string]interface{}{
+			"currentReplicas": int64(3),
+			"currentMetrics": []interface{}{
+				external("s0-rabbitmq-orders", "current", "18"),
+				external("s1-prometheus", "current", "0"),
+				map[string]interface{}{"type": "Resource", "resource": map[string]interface{}{
+					"name": "cpu", "current": map[string]interface{}{"averageUtilization": int64(41)}}},
+			},
+		},
+	}
+
+	got := kedaTriggerStates(triggers, health, hpa)
+	if len(got) != len(triggers) {
+		t.Fatalf("kedaTriggerStates() returned %d states, want one per trigger (%d)", len(got), len(triggers))
+	}
+	tests := []struct {
+		name          string
+		state         map[string]interface{}
+		metricName    string
+		current       string
+		target        string
+		active        bool
+		activityKnown bool
+		health        string
+	}{
+		// 18 per Pod across 3 Pods is 54, above the activation value of 50.
+		{name: "per-Pod average is multiplied back up before the activation check", state: got[0],
+			metricName: "s0-rabbitmq-orders", current: "18", target: "20", active: true, activityKnown: true},
+		{name: "zero is inactive and health comes from status.health", state: got[1],
+			metricName: "s1-prometheus", current: "0", target: "100", active: false, activityKnown: true, health: "Failing"},
+		{name: "resource metric has no activation threshold", state: got[2],
+			metricName: "cpu", current: "41%", target: "70%"},
+		{name: "trigger the HPA doesn't list", state: got[3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.state
+			if s["metricName"] != tt.metricName || s["current"] != tt.current || s["target"] != tt.target ||
+				s["active"] != tt.active || s["activityKnown"] != tt.activityKnown || s["health"] != tt.health {
+				t.Errorf("kedaTriggerStates() state = %v, want metricName:%q current:%q target:%q active:%v activityKnown:%v health:%q",
+					s, tt.metricName, tt.current, tt.target, tt.active, tt.activityKnown, tt.health)
+			}
+		})
+	}
+}
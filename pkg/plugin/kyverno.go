@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"sort"
+	"strings"
+)
+
+// kyvernoPolicyReportRollup totals the results the given PolicyReports/ClusterPolicyReports hold
+// for one Kyverno policy -- "name" for a ClusterPolicy, "namespace/name" for a namespaced Policy,
+// which is how Kyverno fills in a result's policy field. Kyverno writes one report per resource, so
+// a policy's results are spread across as many reports as it matches resources, and nothing on
+// the policy itself says how many of them fail.
+//
+// Returns "pass", "fail", "warn", "error" and "skip" counts, "reports" (how many reports had any
+// result for the policy), and "failing": one dict per resource with a fail or error result --
+// "kind", "name", "namespace", "rules" (the failing rule names) and "message" (the first failure
+// message) -- most failed rules first. A result's resource is its own resources entry when set,
+// otherwise the report's scope.
+func kyvernoPolicyReportRollup(reports []RenderableObject, policy string) map[string]interface{} {
+	counts := map[string]int{}
+	reportCount := 0
+	type failure struct {
+		kind, name, namespace, message string
+		rules                          []string
+	}
+	failures := map[string]*failure{}
+	for _, report := range reports {
+		results, _ := report.Object["results"].([]interface{})
+		scope, _ := report.Object["scope"].(map[string]interface{})
+		matched := false
+		for _, result := range toInterfaceMapSlice(results) {
+			if result["policy"] != policy {
+				continue
+			}
+			matched = true
+			outcome, _ := result["result"].(string)
+			counts[outcome]++
+			if outcome != "fail" && outcome != "error" {
+				continue
+			}
+			resources := toInterfaceMapSlice(result["resources"])
+			if len(resources) == 0 && scope != nil {
+				resources = []map[string]interface{}{scope}
+			}
+			rule, _ := result["rule"].(string)
+			message, _ := result["message"].(string)
+			for _, resource := range resources {
+				kind, _ := resource["kind"].(string)
+				name, _ := resource["name"].(string)
+				namespace, _ := resource["namespace"].(string)
+				key := kind + "/" + namespace + "/" + name
+				f, ok := failures[key]
+				if !ok {
+					f = &failure{kind: kind, name: name, namespace: namespace, message: message}
+					failures[key] = f
+				}
+				if rule != "" && !stringSliceContains(f.rules, rule) {
+					f.rules = append(f.rules, rule)
+				}
+			}
+		}
+		if matched {
+			reportCount++
+		}
+	}
+	sorted := make([]*failure, 0, len(failures))
+	for _, f := range failures {
+		sort.Strings(f.rules)
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if len(a.rules) != len(b.rules) {
+			return len(a.rules) > len(b.rules)
+		}
+		return strings.Join([]string{a.namespace, a.kind, a.name}, "/") < strings.Join([]string{b.namespace, b.kind, b.name}, "/")
+	})
+	failing := make([]map[string]interface{}, 0, len(sorted))
+	for _, f := range sorted {
+		failing = append(failing, map[string]interface{}{
+			"kind":      f.kind,
+			"name":      f.name,
+			"namespace": f.namespace,
+			"rules":     f.rules,
+			"message":   f.message,
+		})
+	}
+	return map[string]interface{}{
+		"pass":    counts["pass"],
+		"fail":    counts["fail"],
+		"warn":    counts["warn"],
+		"error":   counts["error"],
+		"skip":    counts["skip"],
+		"reports": reportCount,
+		"failing": failing,
+	}
+}
@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestKyvernoPolicyReportRollup(t *testing.T) {
+	result := func(policy, rule, outcome, message string) interface{} {
+		return map[string]interface{}{"policy": policy, "rule": rule, "result": outcome, "message": message}
+	}
+	report := func(kind, name string, results ...interface{}) RenderableObject {
+		return RenderableObject{Unstructured: unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "wgpolicyk8s.io/v1alpha2",
+			"kind":       "PolicyReport",
+			"scope":      map[string]interface{}{"kind": kind, "name": name, "namespace": "shop"},
+			"results":    results,
+		}}}
+	}
+	reports := []RenderableObject{
+		report("Pod", "api-1",
+			result("require-labels", "check-team", "fail", "label 'team' is required"),
+			result("require-labels", "check-cost-center", "fail", "label 'cost-center' is required"),
+			result("disallow-latest", "no-latest-tag", "pass", "")),
+		report("Pod", "api-2",
+			result("require-labels", "check-team", "pass", ""),
+			result("require-labels", "check-cost-center", "error", "variable substitution failed")),
+		report("Deployment", "api",
+			result("require-labels", "check-team", "skip", "")),
+		report("Pod", "web-1",
+			result("disallow-latest", "no-latest-tag", "fail", "images must not use the latest tag")),
+	}
+	got := kyvernoPolicyReportRollup(reports, "require-labels")
+	want := map[string]interface{}{
+		"pass":    1,
+		"fail":    2,
+		"warn":    0,
+		"error":   1,
+		"skip":    1,
+		"reports": 3,
+		"failing": []map[string]interface{}{
+			{"kind": "Pod", "name": "api-1", "namespace": "shop", "rules": []string{"check-cost-center", "check-team"}, "message": "label 'team' is required"},
+			{"kind": "Pod", "name": "api-2", "namespace": "shop", "rules": []string{"check-cost-center"}, "message": "variable substitution failed"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("kyvernoPolicyReportRollup() = %v, want %v", got, want)
+	}
+	if got := kyvernoPolicyReportRollup(reports, "shop/require-labels"); got["reports"] != 0 {
+		t.Errorf("kyvernoPolicyReportRollup() for a namespaced Policy name matched %v reports, want 0", got["reports"])
+	}
+}
@@ -2,6 +2,8 @@ package plugin
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -191,6 +193,44 @@ func ciliumRuleSpecs(obj map[string]interface{}) (rules []map[string]interface{}
 	return rules
 }
 
+// ciliumLabelSelector formats a Cilium endpointSelector/nodeSelector the way labelSelector does a
+// Kubernetes one. It can't just reuse metav1.FormatLabelSelector: Cilium's "k8s:app" or
+// "reserved:host" source-prefixed keys aren't valid Kubernetes label keys, so that renders them as
+// "<error>". Keys are kept as written, prefix included.
+func ciliumLabelSelector(selMap map[string]interface{}) string {
+	var parts []string
+	if matchLabels, ok := selMap["matchLabels"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(matchLabels))
+		for k := range matchLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, matchLabels[k]))
+		}
+	}
+	expressions, _ := selMap["matchExpressions"].([]interface{})
+	for _, e := range toInterfaceMapSlice(expressions) {
+		key := fmt.Sprint(e["key"])
+		var values []string
+		rawValues, _ := e["values"].([]interface{})
+		for _, v := range rawValues {
+			values = append(values, fmt.Sprint(v))
+		}
+		switch e["operator"] {
+		case "In":
+			parts = append(parts, fmt.Sprintf("%s in (%s)", key, strings.Join(values, ",")))
+		case "NotIn":
+			parts = append(parts, fmt.Sprintf("%s notin (%s)", key, strings.Join(values, ",")))
+		case "Exists":
+			parts = append(parts, key)
+		case "DoesNotExist":
+			parts = append(parts, "!"+key)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
 // ciliumEndpointSelectorMatchesPod reports whether a Cilium Rule's endpointSelector matches
 // podLabels. endpointSelector uses the same matchLabels/matchExpressions shape as a Kubernetes
 // LabelSelector (https://docs.cilium.io/en/latest/security/policy/kubernetes/), and a
@@ -261,3 +301,177 @@ func stringifyLabels(labels map[string]interface{}) map[string]string {
 	}
 	return out
 }
+
+// policyPeerSelector is the "which Pods" half of a policy's own selector or of one of its rules'
+// peers, normalized across upstream NetworkPolicy, Cilium and Calico so that a single lookup
+// (RenderableObject.policyPeerPods) can list what it currently covers. namespace confines it to
+// Pods in one namespace ("" for any); namespaces, when set, is a namespace selector Pods must
+// also be in; pods decides on a Pod given its labels, its namespace and that namespace's labels.
+// err is set when the selector can't be evaluated against Pods at all.
+type policyPeerSelector struct {
+	namespace  string
+	namespaces func(namespace string, namespaceLabels map[string]string) bool
+	pods       func(podLabels map[string]string, namespace string, namespaceLabels map[string]string) bool
+	err        error
+}
+
+// networkPolicyPeerSelector normalizes a NetworkPolicy peer (podSelector and/or
+// namespaceSelector) in a policy in namespace. A podSelector alone means Pods in the policy's
+// own namespace; a namespaceSelector alone means every Pod in the namespaces it matches; both
+// together mean the matching Pods in those namespaces. The policy's own spec.podSelector is the
+// first case, so it's passed here the same way.
+func networkPolicyPeerSelector(namespace string, peer map[string]interface{}) policyPeerSelector {
+	podSel, err := labelSelectorFromMap(peer["podSelector"])
+	if err != nil {
+		return policyPeerSelector{err: err}
+	}
+	result := policyPeerSelector{
+		namespace: namespace,
+		pods: func(podLabels map[string]string, _ string, _ map[string]string) bool {
+			return podSel.Matches(labels.Set(podLabels))
+		},
+	}
+	if nsSelMap, ok := peer["namespaceSelector"]; ok {
+		nsSel, err := labelSelectorFromMap(nsSelMap)
+		if err != nil {
+			return policyPeerSelector{err: err}
+		}
+		result.namespace = ""
+		result.namespaces = func(_ string, namespaceLabels map[string]string) bool {
+			return nsSel.Matches(labels.Set(namespaceLabels))
+		}
+	}
+	return result
+}
+
+// labelSelectorFromMap converts an unstructured metav1.LabelSelector; nil (an absent selector)
+// becomes labels.Everything(), the same as an empty one.
+func labelSelectorFromMap(selMap interface{}) (labels.Selector, error) {
+	m, _ := selMap.(map[string]interface{})
+	ls := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, ls); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(ls)
+}
+
+const (
+	ciliumPodNamespaceLabel    = "io.kubernetes.pod.namespace"
+	ciliumNamespaceLabelPrefix = "io.cilium.k8s.namespace.labels."
+)
+
+// ciliumEndpointSelector normalizes a Cilium endpointSelector (a Rule's own, or one of its
+// fromEndpoints/toEndpoints) in a policy in namespace ("" for a CiliumClusterwideNetworkPolicy).
+// Cilium matches it against an endpoint's identity labels rather than the Pod's bare labels:
+// keys may carry a "k8s:" or "any:" source prefix, the Pod's namespace is the
+// "io.kubernetes.pod.namespace" label and its namespace's labels appear under
+// "io.cilium.k8s.namespace.labels.". Those are all rebuilt here. Cilium scopes a namespaced
+// policy's selectors to the policy's own namespace unless they name a namespace themselves, so
+// one that mentions io.kubernetes.pod.namespace is looked up across namespaces. Other sources
+// ("reserved:", "cidr:", ...) select identities that aren't Pods, reported through err. See
+// https://docs.cilium.io/en/stable/security/policy/kubernetes/.
+func ciliumEndpointSelector(namespace string, selMap map[string]interface{}) policyPeerSelector {
+	normalized := map[string]interface{}{}
+	namesNamespace := false
+	stripKey := func(key string) (string, error) {
+		for _, prefix := range []string{"k8s:", "any:"} {
+			key = strings.TrimPrefix(key, prefix)
+		}
+		if source, _, found := strings.Cut(key, ":"); found && !strings.Contains(source, "/") {
+			return "", fmt.Errorf("selects %s identities, not Pods", source)
+		}
+		if key == ciliumPodNamespaceLabel {
+			namesNamespace = true
+		}
+		return key, nil
+	}
+	if matchLabels, ok := selMap["matchLabels"].(map[string]interface{}); ok {
+		stripped := map[string]interface{}{}
+		for k, v := range matchLabels {
+			key, err := stripKey(k)
+			if err != nil {
+				return policyPeerSelector{err: err}
+			}
+			stripped[key] = v
+		}
+		normalized["matchLabels"] = stripped
+	}
+	if expressions, ok := selMap["matchExpressions"].([]interface{}); ok {
+		var stripped []interface{}
+		for _, e := range toInterfaceMapSlice(expressions) {
+			key, err := stripKey(fmt.Sprint(e["key"]))
+			if err != nil {
+				return policyPeerSelector{err: err}
+			}
+			copied := map[string]interface{}{}
+			for k, v := range e {
+				copied[k] = v
+			}
+			copied["key"] = key
+			stripped = append(stripped, copied)
+		}
+		normalized["matchExpressions"] = stripped
+	}
+	sel, err := labelSelectorFromMap(normalized)
+	if err != nil {
+		return policyPeerSelector{err: err}
+	}
+	if namesNamespace {
+		namespace = ""
+	}
+	return policyPeerSelector{
+		namespace: namespace,
+		pods: func(podLabels map[string]string, podNamespace string, namespaceLabels map[string]string) bool {
+			identity := make(map[string]string, len(podLabels)+len(namespaceLabels)+1)
+			for k, v := range podLabels {
+				identity[k] = v
+			}
+			for k, v := range namespaceLabels {
+				identity[ciliumNamespaceLabelPrefix+k] = v
+			}
+			identity[ciliumPodNamespaceLabel] = podNamespace
+			return sel.Matches(labels.Set(identity))
+		},
+	}
+}
+
+// calicoEntitySelector normalizes the selector/notSelector/namespaceSelector of a Calico
+// policy's spec, or of a rule's source/destination, in a policy in namespace ("" for a
+// GlobalNetworkPolicy). A namespaceSelector extends the match to the namespaces it selects;
+// without one, a namespaced policy's selector only sees its own namespace and a global one's sees
+// every namespace. namespaceSelector "global()" selects non-namespaced endpoints (host endpoints,
+// global network sets) instead, which aren't Pods. See
+// https://docs.tigera.io/calico/latest/reference/resources/networkpolicy#entityrule.
+func calicoEntitySelector(namespace string, entity map[string]interface{}) policyPeerSelector {
+	selectorStr, _ := entity["selector"].(string)
+	sel, err := calicoselector.Parse(selectorStr)
+	if err != nil {
+		return policyPeerSelector{err: err}
+	}
+	var notSel *calicoselector.Selector
+	if notSelectorStr, _ := entity["notSelector"].(string); notSelectorStr != "" {
+		if notSel, err = calicoselector.Parse(notSelectorStr); err != nil {
+			return policyPeerSelector{err: err}
+		}
+	}
+	result := policyPeerSelector{
+		namespace: namespace,
+		pods: func(podLabels map[string]string, podNamespace string, _ map[string]string) bool {
+			augmented := withCalicoNamespaceLabel(podLabels, podNamespace)
+			return sel.Evaluate(augmented) && (notSel == nil || !notSel.Evaluate(augmented))
+		},
+	}
+	if nsSelectorStr, _ := entity["namespaceSelector"].(string); nsSelectorStr != "" {
+		if strings.TrimSpace(nsSelectorStr) == "global()" {
+			return policyPeerSelector{err: fmt.Errorf("selects non-namespaced endpoints, not Pods")}
+		}
+		if _, err := calicoselector.Parse(nsSelectorStr); err != nil {
+			return policyPeerSelector{err: err}
+		}
+		result.namespace = ""
+		result.namespaces = func(ns string, namespaceLabels map[string]string) bool {
+			return calicoNamespaceSelectorMatches(entity, ns, namespaceLabels)
+		}
+	}
+	return result
+}
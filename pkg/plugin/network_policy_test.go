@@ -420,3 +420,220 @@ func TestCalicoNamespaceSelectorMatches(t *testing.T) {
 		})
 	}
 }
+
+// peerSelectorMatches applies a policyPeerSelector to one Pod the way policyPeerPods does.
+func peerSelectorMatches(sel policyPeerSelector, podNamespace string, podLabels, namespaceLabels map[string]string) bool {
+	if sel.namespace != "" && sel.namespace != podNamespace {
+		return false
+	}
+	if sel.namespaces != nil && !sel.namespaces(podNamespace, namespaceLabels) {
+		return false
+	}
+	return sel.pods(podLabels, podNamespace, namespaceLabels)
+}
+
+func TestNetworkPolicyPeerSelector(t *testing.T) {
+	frontend := map[string]interface{}{"matchLabels": map[string]interface{}{"role": "frontend"}}
+	ops := map[string]interface{}{"matchLabels": map[string]interface{}{"team": "ops"}}
+	tests := []struct {
+		name         string
+		peer         map[string]interface{}
+		podNamespace string
+		podLabels    map[string]string
+		nsLabels     map[string]string
+		want         bool
+	}{
+		{
+			name:         "podSelector alone stays in the policy's namespace",
+			peer:         map[string]interface{}{"podSelector": frontend},
+			podNamespace: "other",
+			podLabels:    map[string]string{"role": "frontend"},
+			want:         false,
+		},
+		{
+			name:         "podSelector alone matches in the policy's namespace",
+			peer:         map[string]interface{}{"podSelector": frontend},
+			podNamespace: "shop",
+			podLabels:    map[string]string{"role": "frontend"},
+			want:         true,
+		},
+		{
+			name:         "namespaceSelector alone admits every pod in a matching namespace",
+			peer:         map[string]interface{}{"namespaceSelector": ops},
+			podNamespace: "monitoring",
+			podLabels:    map[string]string{"app": "anything"},
+			nsLabels:     map[string]string{"team": "ops"},
+			want:         true,
+		},
+		{
+			name:         "both selectors need both to match",
+			peer:         map[string]interface{}{"namespaceSelector": ops, "podSelector": frontend},
+			podNamespace: "monitoring",
+			podLabels:    map[string]string{"role": "backend"},
+			nsLabels:     map[string]string{"team": "ops"},
+			want:         false,
+		},
+		{
+			name:         "empty namespaceSelector means every namespace",
+			peer:         map[string]interface{}{"namespaceSelector": map[string]interface{}{}, "podSelector": frontend},
+			podNamespace: "anywhere",
+			podLabels:    map[string]string{"role": "frontend"},
+			want:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := networkPolicyPeerSelector("shop", tt.peer)
+			if sel.err != nil {
+				t.Fatalf("networkPolicyPeerSelector() err = %v", sel.err)
+			}
+			if got := peerSelectorMatches(sel, tt.podNamespace, tt.podLabels, tt.nsLabels); got != tt.want {
+				t.Errorf("networkPolicyPeerSelector() matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCiliumEndpointSelector(t *testing.T) {
+	tests := []struct {
+		name         string
+		selector     map[string]interface{}
+		podNamespace string
+		podLabels    map[string]string
+		nsLabels     map[string]string
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name:         "k8s: prefix is stripped",
+			selector:     map[string]interface{}{"matchLabels": map[string]interface{}{"k8s:app": "web"}},
+			podNamespace: "shop",
+			podLabels:    map[string]string{"app": "web"},
+			want:         true,
+		},
+		{
+			name:         "without a namespace label it stays in the policy's namespace",
+			selector:     map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+			podNamespace: "other",
+			podLabels:    map[string]string{"app": "web"},
+			want:         false,
+		},
+		{
+			name: "the pod namespace label reaches other namespaces",
+			selector: map[string]interface{}{"matchLabels": map[string]interface{}{
+				"k8s:io.kubernetes.pod.namespace": "kube-system", "k8s:k8s-app": "kube-dns",
+			}},
+			podNamespace: "kube-system",
+			podLabels:    map[string]string{"k8s-app": "kube-dns"},
+			want:         true,
+		},
+		{
+			name: "namespace labels are matched under their prefix",
+			selector: map[string]interface{}{"matchExpressions": []interface{}{
+				map[string]interface{}{"key": "k8s:io.cilium.k8s.namespace.labels.team", "operator": "In", "values": []interface{}{"ops"}},
+			}},
+			podNamespace: "shop",
+			podLabels:    map[string]string{},
+			nsLabels:     map[string]string{"team": "ops"},
+			want:         true,
+		},
+		{
+			name:     "reserved identities aren't pods",
+			selector: map[string]interface{}{"matchLabels": map[string]interface{}{"reserved:host": ""}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := ciliumEndpointSelector("shop", tt.selector)
+			if (sel.err != nil) != tt.wantErr {
+				t.Fatalf("ciliumEndpointSelector() err = %v, wantErr %v", sel.err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := peerSelectorMatches(sel, tt.podNamespace, tt.podLabels, tt.nsLabels); got != tt.want {
+				t.Errorf("ciliumEndpointSelector() matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalicoEntitySelector(t *testing.T) {
+	tests := []struct {
+		name         string
+		namespace    string
+		entity       map[string]interface{}
+		podNamespace string
+		podLabels    map[string]string
+		nsLabels     map[string]string
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name:         "namespaced policy selector stays in its namespace",
+			namespace:    "shop",
+			entity:       map[string]interface{}{"selector": "role == 'frontend'"},
+			podNamespace: "other",
+			podLabels:    map[string]string{"role": "frontend"},
+			want:         false,
+		},
+		{
+			name:         "global policy selector spans namespaces",
+			entity:       map[string]interface{}{"selector": "role == 'frontend'"},
+			podNamespace: "other",
+			podLabels:    map[string]string{"role": "frontend"},
+			want:         true,
+		},
+		{
+			name:         "namespaceSelector widens a namespaced policy's selector",
+			namespace:    "shop",
+			entity:       map[string]interface{}{"selector": "app == 'prometheus'", "namespaceSelector": "projectcalico.org/name == 'monitoring'"},
+			podNamespace: "monitoring",
+			podLabels:    map[string]string{"app": "prometheus"},
+			want:         true,
+		},
+		{
+			name:         "notSelector excludes",
+			namespace:    "shop",
+			entity:       map[string]interface{}{"selector": "has(app)", "notSelector": "app == 'db'"},
+			podNamespace: "shop",
+			podLabels:    map[string]string{"app": "db"},
+			want:         false,
+		},
+		{
+			name:      "global() namespaceSelector isn't pods",
+			namespace: "shop",
+			entity:    map[string]interface{}{"namespaceSelector": "global()"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := calicoEntitySelector(tt.namespace, tt.entity)
+			if (sel.err != nil) != tt.wantErr {
+				t.Fatalf("calicoEntitySelector() err = %v, wantErr %v", sel.err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := peerSelectorMatches(sel, tt.podNamespace, tt.podLabels, tt.nsLabels); got != tt.want {
+				t.Errorf("calicoEntitySelector() matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCiliumLabelSelector(t *testing.T) {
+	got := ciliumLabelSelector(map[string]interface{}{
+		"matchLabels": map[string]interface{}{"k8s:app": "web", "k8s:io.kubernetes.pod.namespace": "shop"},
+		"matchExpressions": []interface{}{
+			map[string]interface{}{"key": "k8s:tier", "operator": "NotIn", "values": []interface{}{"db", "cache"}},
+			map[string]interface{}{"key": "k8s:canary", "operator": "DoesNotExist"},
+		},
+	})
+	want := "k8s:app=web,k8s:io.kubernetes.pod.namespace=shop,k8s:tier notin (db,cache),!k8s:canary"
+	if got != want {
+		t.Errorf("ciliumLabelSelector() = %q, want %q", got, want)
+	}
+}
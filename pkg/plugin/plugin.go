@@ -43,48 +43,104 @@ func Run(f util.Factory, streams genericiooptions.IOStreams, args []string, cfg
 		return err
 	}
 	klog.V(5).InfoS("Created engine", "engine", engine)
+	err = runQuery(repo, engine, args, cfg)
+	// Under --output the collected reports are printed even when the run ends in an error, as
+	// long as there is something to print: a HealthExitError or a --wait-for timeout is a verdict
+	// about the reported objects, not a reason to withhold them.
+	if format := engine.reportOutput(); format != "" && (err == nil || len(engine.reports) > 0) {
+		if writeErr := writeReports(streams.Out, format, engine.reports); writeErr != nil {
+			return writeErr
+		}
+	}
+	return err
+}
+
+// runQuery dispatches to the mode the flags ask for; every mode renders through renderObj.
+func runQuery(repo *input.ResourceRepo, engine *renderEngine, args []string, cfg *RenderConfig) error {
+	var err error
+	if IsClusterDashboardQuery(args) {
+		return runClusterDashboard(engine, repo)
+	}
+	if cfg.Viper.GetBool("problems") {
+		args, err = problemsQueryArgs(repo, args, cfg)
+		if err != nil {
+			klog.V(1).ErrorS(err, "Error discovering resource types")
+			return err
+		}
+		return runProblems(repo.CLIQueryResults(args), engine, repo)
+	}
+	if cfg.Viper.GetBool("tui") {
+		return runTUI(args, engine, repo)
+	}
+	if cfg.Baseline != nil {
+		return runSinceSnapshot(args, engine, repo, cfg)
+	}
 	results := repo.CLIQueryResults(args)
+	if cfg.Viper.GetString("wait-for") != "" {
+		return runWaitForHealthy(results, engine, repo, cfg)
+	}
 	count := 0
+	tally := newHealthTally()
+	// With live queries on, everything is listed before anything is rendered, so the related
+	// objects all of it needs can be prefetched in one concurrent batch; otherwise each object
+	// is rendered as soon as it is visited.
+	prefetch := !cfg.Viper.GetBool("shallow") && !cfg.Viper.GetBool("local")
+	var visited []RenderableObject
 	err = results.Visit(func(resourceInfo *resource.Info, err error) error {
 		count += 1
 		klog.V(5).InfoS("Processing resource", "item", count, "resource", resourceInfo)
-		processObj(resourceInfo.Object, engine, repo)
+		if prefetch {
+			if r, ok := decodeObj(resourceInfo.Object, engine, repo); ok {
+				visited = append(visited, r)
+			}
+		} else if r, ok := processObj(resourceInfo.Object, engine, repo); ok {
+			visited = append(visited, r)
+			tally.add(r)
+		}
 		return err
 	})
+	if prefetch {
+		prefetchRelated(visited, engine, repo)
+		for _, r := range visited {
+			renderObj(r, engine)
+			tally.add(r)
+		}
+	}
 	klog.V(5).InfoS("Processed matching resources", "count", count)
+	exitCode := cfg.Viper.GetBool("exit-code")
+	if exitCode {
+		err = tally.addVisitError(err)
+	}
 	if err != nil {
 		klog.V(1).ErrorS(err, "Error querying resources")
 		return err
 	}
+	if exitCode {
+		return tally.exitError()
+	}
 	isWatch := cfg.Viper.GetBool("watch")
 	if !isWatch && count == 0 {
 		return fmt.Errorf("no resources found")
 	}
 	if cfg.Viper.GetBool("watch") {
+		if cfg.Viper.GetBool("deep") {
+			return runDeepWatch(results, visited, engine, repo, cfg)
+		}
 		return runWatch(results, engine, repo, cfg)
 	}
 	return nil
 }
 
+// runWatch re-renders each object the query's watch reports, in shallow mode: every render would
+// otherwise repeat all of its related-object lookups against the API server. --deep switches to
+// runDeepWatch, which serves the common ones from informers instead.
 func runWatch(results *resource.Result, engine *renderEngine, repo *input.ResourceRepo, cfg *RenderConfig) error {
-	color.HiYellow("\nPrinted all existing resource statuses, starting to watch. Switching to shallow mode during watch!\n\n")
+	color.HiYellow("\nPrinted all existing resource statuses, starting to watch. Switching to shallow mode during watch, use --deep to keep full rendering!\n\n")
 	cfg.Viper.Set("shallow", true)
 	cfg.Viper.Set("watching", true)
 	klog.V(5).InfoS("Will run watch")
-	obj, err := results.Object()
+	w, obj, err := startWatch(results)
 	if err != nil {
-		klog.V(1).ErrorS(err, "Failed to get results object")
-		return err
-	}
-	rv, err := meta.NewAccessor().ResourceVersion(obj)
-	if err != nil {
-		klog.V(1).ErrorS(err, "Watch failed to obtain resource version for list")
-		return err
-	}
-	klog.V(5).InfoS("Starting watch with a specific resource version", "rv", rv)
-	w, err := results.Watch(rv)
-	if err != nil {
-		klog.V(1).ErrorS(err, "Can't start watch")
 		return err
 	}
 	ctx, cancel := context.WithCancel(context.Background())
@@ -93,7 +149,7 @@ func runWatch(results *resource.Result, engine *renderEngine, repo *input.Resour
 	_ = intr.Run(func() error {
 		_, err := watchtools.UntilWithoutRetry(ctx, w, func(e watch.Event) (bool, error) {
 			klog.V(5).InfoS("Processing watch event", "e", e)
-			processObj(e.Object, engine, repo)
+			renderWatchEvent(e, engine, repo)
 			return false, nil
 		})
 		klog.V(1).ErrorS(err, "Watch failed", "obj", obj)
@@ -102,21 +158,92 @@ func runWatch(results *resource.Result, engine *renderEngine, repo *input.Resour
 	return nil
 }
 
-func processObj(obj runtime.Object, engine *renderEngine, repo *input.ResourceRepo) {
-	streams := engine.ioStreams
+// renderWatchEvent re-renders the object a --watch event reports. What the previous renders cached
+// (the prefetched Services and Ingresses, owners, events, ...) is dropped first, as
+// deepWatch.render does, so an object created since the watch started shows up.
+func renderWatchEvent(e watch.Event, engine *renderEngine, repo *input.ResourceRepo) {
+	repo.ResetCaches()
+	processObj(e.Object, engine, repo)
+}
+
+// startWatch starts watching results from the resource version they were listed at, so no change
+// between the initial list and the watch is missed. It also returns the listed object, for logging.
+func startWatch(results *resource.Result) (watch.Interface, runtime.Object, error) {
+	obj, err := results.Object()
+	if err != nil {
+		klog.V(1).ErrorS(err, "Failed to get results object")
+		return nil, nil, err
+	}
+	rv, err := meta.NewAccessor().ResourceVersion(obj)
+	if err != nil {
+		klog.V(1).ErrorS(err, "Watch failed to obtain resource version for list")
+		return nil, nil, err
+	}
+	klog.V(5).InfoS("Starting watch with a specific resource version", "rv", rv)
+	w, err := results.Watch(rv)
+	if err != nil {
+		klog.V(1).ErrorS(err, "Can't start watch")
+		return nil, nil, err
+	}
+	return w, obj, nil
+}
+
+// processObj renders obj and returns it as the RenderableObject it was rendered as; ok is false
+// when obj couldn't be decoded at all, in which case nothing was rendered.
+func processObj(obj runtime.Object, engine *renderEngine, repo *input.ResourceRepo) (r RenderableObject, ok bool) {
+	r, ok = decodeObj(obj, engine, repo)
+	if ok {
+		renderObj(r, engine)
+	}
+	return r, ok
+}
+
+// decodeObj converts obj into a RenderableObject, reporting on stderr when it can't.
+func decodeObj(obj runtime.Object, engine *renderEngine, repo *input.ResourceRepo) (r RenderableObject, ok bool) {
 	out, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 	if err != nil {
-		errorPrintf(streams.ErrOut, "Failed to decode obj=%s: %s", obj, err)
+		errorPrintf(engine.ioStreams.ErrOut, "Failed to decode obj=%s: %s", obj, err)
+		return r, false
+	}
+	return newRenderableObject(out, engine, repo), true
+}
+
+// listObjects decodes everything results holds, stopping at the first error.
+func listObjects(results *resource.Result, engine *renderEngine, repo *input.ResourceRepo) (objects []RenderableObject, err error) {
+	err = results.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if r, ok := decodeObj(info.Object, engine, repo); ok {
+			objects = append(objects, r)
+		}
+		return nil
+	})
+	return objects, err
+}
+
+// objectKey identifies an object across separately listed copies of it, e.g. a refresh's.
+func objectKey(r RenderableObject) string {
+	return r.GroupVersionKind().GroupKind().String() + "/" + r.Namespace() + "/" + r.Name()
+}
+
+// renderObj prints r's full view, or only its one-line summary under --short, starting from a
+// fresh set of rendered UIDs so deep renders of one top-level object don't suppress those of
+// the next. Under --output it instead only collects r's HealthReport, for Run to print once
+// everything has been visited.
+func renderObj(r RenderableObject, engine *renderEngine) {
+	streams := engine.ioStreams
+	if engine.reportOutput() != "" {
+		engine.reports = append(engine.reports, newHealthReport(r))
 		return
 	}
 	engine.renderedUIDs = make(uidSet)
-	r := newRenderableObject(out, engine, repo)
 	if engine.cfg.Viper.GetBool("short") {
 		processObjShort(r, streams)
 		return
 	}
 	_, _ = fmt.Fprintf(streams.Out, "\n")
-	err = r.render(streams.Out)
+	err := r.render(streams.Out)
 	if err != nil {
 		_, _ = fmt.Fprintf(streams.ErrOut, "\n")
 		errorPrintf(streams.ErrOut, "Failed to render: %s", err)
@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// Resource-type lists the templates hand to repo.Objects with nothing but a namespace, so a single
+// list per namespace answers the question for every object in it. The strings must match the
+// template helpers' arguments exactly: they are the objectsCache key.
+var (
+	// podSelectingListTypes are listed by matching_pdbs and the matching_*network_policies family,
+	// which Pod.tmpl and matching_workload_resources both include.
+	podSelectingListTypes = []string{
+		"poddisruptionbudgets",
+		"networkpolicies",
+		"ciliumnetworkpolicies.cilium.io",
+		"networkpolicies.crd.projectcalico.org",
+	}
+	// clusterPodSelectingListTypes are the cluster-wide members of the same family, listed once
+	// no matter how many namespaces are rendered.
+	clusterPodSelectingListTypes = []string{
+		"ciliumclusterwidenetworkpolicies.cilium.io",
+		"globalnetworkpolicies.crd.projectcalico.org",
+	}
+	// serviceRouteListTypes are what KubeGetRoutesMatchingService lists for Service.tmpl.
+	serviceRouteListTypes = []string{"httproutes", "grpcroutes", "tcproutes", "udproutes", "tlsroutes"}
+)
+
+// workloadKinds are the kinds that include matching_workload_resources, with whether they also
+// include matching_hpas and matching_vpas.
+var workloadKinds = map[string]struct{ hpas, vpas bool }{
+	"Deployment":  {hpas: true, vpas: true},
+	"StatefulSet": {hpas: true, vpas: true},
+	"ReplicaSet":  {hpas: true, vpas: true},
+	"DaemonSet":   {vpas: true},
+	"Job":         {},
+	"CronJob":     {},
+}
+
+// prefetchPlan collects the distinct repo lookups a batch of renders is going to make. Each load
+// is keyed so that a hundred Pods in one namespace add a single Services list, not a hundred.
+type prefetchPlan struct {
+	repo  *input.ResourceRepo
+	seen  map[string]bool
+	loads []func()
+}
+
+func (p *prefetchPlan) add(load func(), key ...string) {
+	k := strings.Join(key, "\x1e")
+	if p.seen[k] {
+		return
+	}
+	p.seen[k] = true
+	p.loads = append(p.loads, load)
+}
+
+func (p *prefetchPlan) addObjects(namespace, resourceType, selector string) {
+	p.add(func() { _, _ = p.repo.Objects(namespace, []string{resourceType}, selector) }, "objects", namespace, resourceType, selector)
+}
+
+func (p *prefetchPlan) addServices(namespace string) {
+	p.add(func() { _, _ = p.repo.Services(namespace) }, "services", namespace)
+}
+
+// addKindLists adds the per-namespace lists r's own template is known to make. Kinds not listed
+// here simply render with the serial lookups as before; this is an optimisation, not a contract.
+func (p *prefetchPlan) addKindLists(r RenderableObject) {
+	namespace := r.Namespace()
+	addPodSelecting := func() {
+		for _, resourceType := range podSelectingListTypes {
+			p.addObjects(namespace, resourceType, "")
+		}
+		for _, resourceType := range clusterPodSelectingListTypes {
+			p.addObjects("", resourceType, "")
+		}
+	}
+	workload, isWorkload := workloadKinds[r.Kind()]
+	switch {
+	case r.Kind() == "Pod":
+		// KubeGetServicesMatchingPod goes through EndpointSlices, then fetches each Service by
+		// name, which repo.Service answers from the Services list once that is cached.
+		p.add(func() { _, _ = p.repo.EndpointSlices(namespace) }, "endpointslices", namespace)
+		p.addServices(namespace)
+		addPodSelecting()
+	case r.Kind() == "Service":
+		p.add(func() { _, _ = p.repo.Ingresses(namespace) }, "ingresses", namespace)
+		for _, resourceType := range serviceRouteListTypes {
+			p.addObjects(namespace, resourceType, "")
+		}
+		p.addObjects(namespace, "endpointslices", discoveryv1.LabelServiceName+"="+r.Name())
+	case isWorkload:
+		p.addServices(namespace)
+		addPodSelecting()
+		if workload.hpas {
+			p.addObjects(namespace, "HorizontalPodAutoscalers", "")
+		}
+		if workload.vpas {
+			p.addObjects(namespace, "VerticalPodAutoscalers", "")
+		}
+	}
+}
+
+// prefetchRelated warms the repo's caches, concurrently, with the related objects the upcoming
+// renders of objects will look up from inside their templates -- owners and events for every
+// kind, plus the namespace-wide lists (Services, PDBs, network policies, ...) the templates of
+// the kinds in workloadKinds, Pod and Service match against. Without it a deep render of a
+// namespace with a few hundred Pods spends nearly all of its time issuing those lookups one at
+// a time. Nothing is prefetched when live queries are disabled, since the templates then make
+// no lookups at all.
+//
+// Under --short and --output only owners and events are prefetched: the one-line summaries and
+// the health reports don't look at the rest, and listing it anyway would only add requests.
+func prefetchRelated(objects []RenderableObject, engine *renderEngine, repo *input.ResourceRepo) {
+	if len(objects) == 0 || objects[0].LiveQueriesDisabled() {
+		return
+	}
+	fullRender := engine.reportOutput() == "" && !engine.cfg.Viper.GetBool("short")
+	plan := newPrefetchPlan(objects, fullRender, repo)
+	klog.V(5).InfoS("Prefetching related objects", "objects", len(objects), "loads", len(plan.loads))
+	repo.Prefetch(plan.loads)
+}
+
+func newPrefetchPlan(objects []RenderableObject, fullRender bool, repo *input.ResourceRepo) *prefetchPlan {
+	plan := &prefetchPlan{repo: repo, seen: map[string]bool{}}
+	perNamespace := map[string]int{}
+	for _, r := range objects {
+		perNamespace[r.Namespace()]++
+	}
+	for _, r := range objects {
+		namespace := r.Namespace()
+		// A whole-namespace Events list only beats the per-object search once there is more than
+		// one object to answer for.
+		if namespace != "" && perNamespace[namespace] > 1 {
+			plan.add(func() { repo.PrefetchEvents(namespace) }, "events", namespace)
+		}
+		for _, owner := range r.GetOwnerReferences() {
+			plan.add(func() { _, _ = repo.ResolveOwner(namespace, owner) }, "owner", namespace, owner.APIVersion, owner.Kind, owner.Name)
+		}
+		if fullRender {
+			plan.addKindLists(r)
+		}
+	}
+	return plan
+}
@@ -0,0 +1,59 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPrefetchObject(kind, namespace, name string, owners ...interface{}) RenderableObject {
+	metadata := map[string]interface{}{"name": name, "namespace": namespace}
+	if len(owners) > 0 {
+		metadata["ownerReferences"] = owners
+	}
+	return newTestRenderableObject(map[string]interface{}{"apiVersion": "v1", "kind": kind, "metadata": metadata})
+}
+
+// TestNewPrefetchPlan verifies that the plan asks for each namespace-wide list once however many
+// objects need it, lists the cluster-wide policy kinds once across namespaces, only lists a
+// namespace's Events when more than one object there will be rendered, and keeps just owners and
+// events when the full templates won't run.
+func TestNewPrefetchPlan(t *testing.T) {
+	rs := map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web-5d8f"}
+	objects := []RenderableObject{
+		testPrefetchObject("Pod", "web", "web-5d8f-a", rs),
+		testPrefetchObject("Pod", "web", "web-5d8f-b", rs),
+		testPrefetchObject("Deployment", "web", "web"),
+		testPrefetchObject("Pod", "other", "lonely"),
+	}
+	has := func(plan *prefetchPlan, key ...string) bool { return plan.seen[strings.Join(key, "\x1e")] }
+
+	plan := newPrefetchPlan(objects, true, nil)
+	if len(plan.loads) != len(plan.seen) {
+		t.Errorf("expected one load per distinct key, got %d loads for %d keys", len(plan.loads), len(plan.seen))
+	}
+	for _, key := range [][]string{
+		{"services", "web"},
+		{"services", "other"},
+		{"endpointslices", "web"},
+		{"objects", "web", "poddisruptionbudgets", ""},
+		{"objects", "web", "HorizontalPodAutoscalers", ""},
+		{"objects", "", "ciliumclusterwidenetworkpolicies.cilium.io", ""},
+		{"events", "web"},
+		{"owner", "web", "apps/v1", "ReplicaSet", "web-5d8f"},
+	} {
+		if !has(plan, key...) {
+			t.Errorf("expected the plan to include %q", key)
+		}
+	}
+	if has(plan, "events", "other") {
+		t.Errorf("expected no Events list for a namespace with a single object")
+	}
+	if got, want := len(plan.loads), 18; got != want {
+		t.Errorf("expected %d loads, got %d: %v", want, got, plan.seen)
+	}
+
+	plan = newPrefetchPlan(objects, false, nil)
+	if len(plan.loads) != 2 || !has(plan, "events", "web") || !has(plan, "owner", "web", "apps/v1", "ReplicaSet", "web-5d8f") {
+		t.Errorf("expected only the Events list and the owner without a full render, got %v", plan.seen)
+	}
+}
@@ -0,0 +1,130 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// problemsQueryArgs returns the resource-builder arguments `--problems` sweeps. Explicit TYPE
+// arguments (or -f manifests, or --local) are honoured as given, so `--problems deploy,sts`
+// narrows the sweep; only a bare `--problems` expands to every listable namespaced type, which
+// is the whole point of the mode: the on-call engineer doesn't have to know which kinds to ask
+// about.
+func problemsQueryArgs(repo *input.ResourceRepo, args []string, cfg *RenderConfig) ([]string, error) {
+	if len(args) > 0 || cfg.Viper.GetBool("local") || len(cfg.Viper.GetStringSlice("filename")) > 0 {
+		return args, nil
+	}
+	types, err := repo.ListableResourceTypes()
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no listable resource types found")
+	}
+	return []string{strings.Join(types, ",")}, nil
+}
+
+// problemGroup is every problematic object of one group-qualified kind, in the order the
+// apiserver listed them.
+type problemGroup struct {
+	kind    string
+	objects []RenderableObject
+}
+
+// groupProblematic keeps only the objects whose kstatus disagrees with Current (see
+// RenderableObject.Problematic) and buckets them by group-qualified kind -- qualified so that
+// e.g. Cluster API's Cluster and CloudNativePG's Cluster don't end up under one heading --
+// sorted by that kind so the output layout is stable between runs.
+func groupProblematic(objects []RenderableObject) []problemGroup {
+	byKind := map[string]*problemGroup{}
+	for _, r := range objects {
+		if !r.Problematic() {
+			continue
+		}
+		kind := qualifyKind(r.Kind(), r.GroupVersionKind().Group)
+		group, ok := byKind[kind]
+		if !ok {
+			group = &problemGroup{kind: kind}
+			byKind[kind] = group
+		}
+		group.objects = append(group.objects, r)
+	}
+	groups := make([]problemGroup, 0, len(byKind))
+	for _, group := range byKind {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].kind < groups[j].kind })
+	return groups
+}
+
+// runProblems is the --problems triage mode: it visits everything the query matched, but renders
+// only the problematic objects, grouped under one heading per kind. Unlike the default mode, a
+// type that fails to list (most often a Forbidden from namespace-scoped RBAC, or an aggregated
+// API that is down) doesn't abort the sweep -- it is reported on stderr after the objects that
+// could be listed, since a partial triage is still far more useful during an incident than none.
+func runProblems(results *resource.Result, engine *renderEngine, repo *input.ResourceRepo) error {
+	streams := engine.ioStreams
+	var objects []RenderableObject
+	visitErr := results.Visit(func(resourceInfo *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		out, convErr := runtime.DefaultUnstructuredConverter.ToUnstructured(resourceInfo.Object)
+		if convErr != nil {
+			errorPrintf(streams.ErrOut, "Failed to decode obj=%s: %s", resourceInfo.Object, convErr)
+			return nil
+		}
+		objects = append(objects, newRenderableObject(out, engine, repo))
+		return nil
+	})
+	klog.V(5).InfoS("Collected resources for problems mode", "count", len(objects))
+	if visitErr != nil && len(objects) == 0 {
+		klog.V(1).ErrorS(visitErr, "Error querying resources")
+		return visitErr
+	}
+	groups := groupProblematic(objects)
+	// The headings are for humans; under --output the reports alone carry the kinds.
+	text := engine.reportOutput() == ""
+	if len(groups) == 0 && text {
+		_, _ = fmt.Fprintf(streams.Out, "No problematic resources found among %d checked.\n", len(objects))
+	}
+	var problematic []RenderableObject
+	for _, group := range groups {
+		problematic = append(problematic, group.objects...)
+	}
+	prefetchRelated(problematic, engine, repo)
+	short := engine.cfg.Viper.GetBool("short")
+	for i, group := range groups {
+		if text {
+			if i > 0 || !short {
+				_, _ = fmt.Fprintln(streams.Out)
+			}
+			_, _ = color.New(color.Bold, color.Underline).Fprintf(streams.Out, "%s (%d)", group.kind, len(group.objects))
+			_, _ = fmt.Fprintln(streams.Out)
+		}
+		for _, r := range group.objects {
+			renderObj(r, engine)
+		}
+	}
+	if visitErr != nil {
+		klog.V(1).ErrorS(visitErr, "Some resource types could not be listed")
+		_, _ = fmt.Fprintln(streams.ErrOut)
+		errorPrintf(streams.ErrOut, "Some resources could not be checked: %s", visitErr)
+	}
+	if engine.cfg.Viper.GetBool("exit-code") {
+		tally := newHealthTally()
+		for _, r := range objects {
+			tally.add(r)
+		}
+		return tally.exitError()
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// prometheusOperatorSelectorFields is, for each kind the Prometheus operator discovers by label,
+// the prefix of the "<prefix>Selector"/"<prefix>NamespaceSelector" pair a selecting CR carries
+// for it, and the kinds of CR that carry that pair.
+var prometheusOperatorSelectorFields = map[string]struct {
+	prefix    string
+	selectors []string
+}{
+	"ServiceMonitor":     {"serviceMonitor", []string{"Prometheus", "PrometheusAgent"}},
+	"PodMonitor":         {"podMonitor", []string{"Prometheus", "PrometheusAgent"}},
+	"Probe":              {"probe", []string{"Prometheus", "PrometheusAgent"}},
+	"ScrapeConfig":       {"scrapeConfig", []string{"Prometheus", "PrometheusAgent"}},
+	"PrometheusRule":     {"rule", []string{"Prometheus", "ThanosRuler"}},
+	"AlertmanagerConfig": {"alertmanagerConfig", []string{"Alertmanager"}},
+}
+
+// prometheusOperatorSelects reports whether a Prometheus, PrometheusAgent, ThanosRuler or
+// Alertmanager in ownerNamespace, with the given spec, picks up an object of kind living in
+// namespace. The operator's rules differ from a plain LabelSelector's in both halves: a null
+// "<prefix>Selector" selects nothing at all (only an empty one selects everything), and a null
+// "<prefix>NamespaceSelector" confines the search to the owner's own namespace rather than
+// opening it to all of them. See
+// https://prometheus-operator.dev/docs/api-reference/api/#monitoring.coreos.com/v1.PrometheusSpec.
+func prometheusOperatorSelects(ownerNamespace string, spec map[string]interface{}, kind, namespace string, objLabels, namespaceLabels map[string]string) (bool, error) {
+	fields, ok := prometheusOperatorSelectorFields[kind]
+	if !ok || spec[fields.prefix+"Selector"] == nil {
+		return false, nil
+	}
+	sel, err := labelSelectorFromMap(spec[fields.prefix+"Selector"])
+	if err != nil {
+		return false, err
+	}
+	if !sel.Matches(labels.Set(objLabels)) {
+		return false, nil
+	}
+	nsSelMap := spec[fields.prefix+"NamespaceSelector"]
+	if nsSelMap == nil {
+		return namespace == ownerNamespace, nil
+	}
+	nsSel, err := labelSelectorFromMap(nsSelMap)
+	if err != nil {
+		return false, err
+	}
+	return nsSel.Matches(labels.Set(namespaceLabels)), nil
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import "testing"
+
+func TestPrometheusOperatorSelects(t *testing.T) {
+	releaseSelector := map[string]interface{}{"matchLabels": map[string]interface{}{"release": "stack"}}
+	releaseLabels := map[string]string{"release": "stack"}
+	tests := []struct {
+		name            string
+		spec            map[string]interface{}
+		kind            string
+		namespace       string
+		objLabels       map[string]string
+		namespaceLabels map[string]string
+		want            bool
+		wantErr         bool
+	}{
+		{
+			name:      "unset selector selects nothing",
+			spec:      map[string]interface{}{},
+			kind:      "ServiceMonitor",
+			namespace: "monitoring",
+			objLabels: releaseLabels,
+			want:      false,
+		},
+		{
+			name:      "empty selector selects everything in the owner's namespace",
+			spec:      map[string]interface{}{"serviceMonitorSelector": map[string]interface{}{}},
+			kind:      "ServiceMonitor",
+			namespace: "monitoring",
+			want:      true,
+		},
+		{
+			name:      "unset namespace selector confines to the owner's namespace",
+			spec:      map[string]interface{}{"serviceMonitorSelector": releaseSelector},
+			kind:      "ServiceMonitor",
+			namespace: "shop",
+			objLabels: releaseLabels,
+			want:      false,
+		},
+		{
+			name:      "empty namespace selector opens every namespace",
+			spec:      map[string]interface{}{"serviceMonitorSelector": releaseSelector, "serviceMonitorNamespaceSelector": map[string]interface{}{}},
+			kind:      "ServiceMonitor",
+			namespace: "shop",
+			objLabels: releaseLabels,
+			want:      true,
+		},
+		{
+			name: "namespace selector matched against the namespace's labels",
+			spec: map[string]interface{}{
+				"podMonitorSelector":          map[string]interface{}{},
+				"podMonitorNamespaceSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"team": "shop"}},
+			},
+			kind:            "PodMonitor",
+			namespace:       "shop",
+			namespaceLabels: map[string]string{"team": "payments"},
+			want:            false,
+		},
+		{
+			name:      "labels not matching the selector",
+			spec:      map[string]interface{}{"serviceMonitorSelector": releaseSelector},
+			kind:      "ServiceMonitor",
+			namespace: "monitoring",
+			objLabels: map[string]string{"release": "other"},
+			want:      false,
+		},
+		{
+			name:      "rules use the rule selector pair",
+			spec:      map[string]interface{}{"serviceMonitorSelector": map[string]interface{}{}, "ruleSelector": releaseSelector},
+			kind:      "PrometheusRule",
+			namespace: "monitoring",
+			objLabels: releaseLabels,
+			want:      true,
+		},
+		{
+			name:      "a kind the operator doesn't select",
+			spec:      map[string]interface{}{"serviceMonitorSelector": map[string]interface{}{}},
+			kind:      "ConfigMap",
+			namespace: "monitoring",
+			want:      false,
+		},
+		{
+			name: "invalid selector",
+			spec: map[string]interface{}{"serviceMonitorSelector": map[string]interface{}{
+				"matchExpressions": []interface{}{map[string]interface{}{"key": "release", "operator": "Bogus"}},
+			}},
+			kind:      "ServiceMonitor",
+			namespace: "monitoring",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prometheusOperatorSelects("monitoring", tt.spec, tt.kind, tt.namespace, tt.objLabels, tt.namespaceLabels)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("prometheusOperatorSelects() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("prometheusOperatorSelects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
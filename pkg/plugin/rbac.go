@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// serviceAccountSubjectMatch reports how a RoleBinding/ClusterRoleBinding's subjects include the
+// ServiceAccount namespace/name: "ServiceAccount" when one names it directly, otherwise the
+// "User/<name>" or "Group/<name>" subject the API server's authorizer would also match it through
+// -- the SA's own username "system:serviceaccount:<namespace>:<name>", or one of the groups every
+// ServiceAccount token carries, "system:serviceaccounts" and "system:serviceaccounts:<namespace>".
+// "" when none does. "system:authenticated" is deliberately not counted: every SA is in it, but
+// so is every user, and the default bindings for it (discovery, basic-user, ...) would show up on
+// every ServiceAccount without saying anything about this one. See
+// https://kubernetes.io/docs/reference/access-authn-authz/rbac/#referring-to-subjects.
+func serviceAccountSubjectMatch(subjects []interface{}, namespace, name string) string {
+	username := fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+	groups := []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace}
+	match := ""
+	for _, subject := range toInterfaceMapSlice(subjects) {
+		subjectName, _ := subject["name"].(string)
+		switch subject["kind"] {
+		case "ServiceAccount":
+			if subjectNamespace, _ := subject["namespace"].(string); subjectName == name && subjectNamespace == namespace {
+				return "ServiceAccount"
+			}
+		case "User":
+			if subjectName == username && match == "" {
+				match = "User/" + subjectName
+			}
+		case "Group":
+			for _, group := range groups {
+				if subjectName == group && match == "" {
+					match = "Group/" + subjectName
+				}
+			}
+		}
+	}
+	return match
+}
+
+// rbacVerbOrder is the order rbacRuleSummary lists verbs in: reads, then writes, the way
+// "kubectl create role --verb" and the built-in roles spell them. Anything else (escalate, bind,
+// impersonate, use, approve, ...) follows alphabetically.
+var rbacVerbOrder = []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}
+
+// rbacRuleSummary folds a Role/ClusterRole's rules into one entry per resource, the granularity a
+// "forbidden" error is about: {"resource", "verbs" []string, "resourceNames" []string,
+// "nonResource" bool}. A resource is "<resource>.<apiGroup>" ("pods" for the core group,
+// "deployments.apps", "pods/log", "*" for every resource of every group); rules repeating the
+// same resource (e.g. one per apiGroup a resource has moved through) are merged, except where
+// resourceNames restrict one, which stay separate entries as they grant something different.
+// A "*" verb swallows the rest. nonResourceURLs (ClusterRole only) are listed after resources.
+func rbacRuleSummary(rules []interface{}) []map[string]interface{} {
+	type entry struct {
+		resource      string
+		resourceNames []string
+		nonResource   bool
+		verbs         map[string]bool
+	}
+	entries := map[string]*entry{}
+	add := func(resource string, resourceNames []string, nonResource bool, verbs []string) {
+		key := fmt.Sprintf("%t|%s|%s", nonResource, resource, strings.Join(resourceNames, ","))
+		e, ok := entries[key]
+		if !ok {
+			e = &entry{resource: resource, resourceNames: resourceNames, nonResource: nonResource, verbs: map[string]bool{}}
+			entries[key] = e
+		}
+		for _, verb := range verbs {
+			e.verbs[verb] = true
+		}
+	}
+	for _, rule := range toInterfaceMapSlice(rules) {
+		verbs := toStringSlice(rule["verbs"])
+		resourceNames := toStringSlice(rule["resourceNames"])
+		sort.Strings(resourceNames)
+		groups := toStringSlice(rule["apiGroups"])
+		for _, resource := range toStringSlice(rule["resources"]) {
+			for _, group := range groups {
+				name := resource
+				switch {
+				case group == "*" && resource == "*":
+				case group != "":
+					name = resource + "." + group
+				}
+				add(name, resourceNames, false, verbs)
+			}
+		}
+		for _, url := range toStringSlice(rule["nonResourceURLs"]) {
+			add(url, nil, true, verbs)
+		}
+	}
+	sorted := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		sorted = append(sorted, e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.nonResource != b.nonResource {
+			return b.nonResource
+		}
+		if a.resource != b.resource {
+			return a.resource < b.resource
+		}
+		return strings.Join(a.resourceNames, ",") < strings.Join(b.resourceNames, ",")
+	})
+	out := make([]map[string]interface{}, 0, len(sorted))
+	for _, e := range sorted {
+		out = append(out, map[string]interface{}{
+			"resource":      e.resource,
+			"verbs":         sortedRBACVerbs(e.verbs),
+			"resourceNames": e.resourceNames,
+			"nonResource":   e.nonResource,
+		})
+	}
+	return out
+}
+
+func sortedRBACVerbs(verbs map[string]bool) []string {
+	if verbs["*"] {
+		return []string{"*"}
+	}
+	var out, rest []string
+	for _, verb := range rbacVerbOrder {
+		if verbs[verb] {
+			out = append(out, verb)
+		}
+	}
+	for verb := range verbs {
+		if !stringSliceContains(rbacVerbOrder, verb) {
+			rest = append(rest, verb)
+		}
+	}
+	sort.Strings(rest)
+	return append(out, rest...)
+}
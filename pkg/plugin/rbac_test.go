@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestServiceAccountSubjectMatch(t *testing.T) {
+	subject := func(kind, name, namespace string) interface{} {
+		s := map[string]interface{}{"kind": kind, "name": name}
+		if namespace != "" {
+			s["namespace"] = namespace
+		}
+		return s
+	}
+	tests := []struct {
+		name     string
+		subjects []interface{}
+		want     string
+	}{
+		{
+			name:     "direct ServiceAccount subject",
+			subjects: []interface{}{subject("Group", "system:serviceaccounts", ""), subject("ServiceAccount", "api", "shop")},
+			want:     "ServiceAccount",
+		},
+		{
+			name:     "same name in another namespace",
+			subjects: []interface{}{subject("ServiceAccount", "api", "other")},
+			want:     "",
+		},
+		{
+			name:     "the ServiceAccount's username",
+			subjects: []interface{}{subject("User", "system:serviceaccount:shop:api", "")},
+			want:     "User/system:serviceaccount:shop:api",
+		},
+		{
+			name:     "all ServiceAccounts in the namespace",
+			subjects: []interface{}{subject("Group", "system:serviceaccounts:shop", "")},
+			want:     "Group/system:serviceaccounts:shop",
+		},
+		{
+			name:     "system:authenticated isn't counted",
+			subjects: []interface{}{subject("Group", "system:authenticated", "")},
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceAccountSubjectMatch(tt.subjects, "shop", "api"); got != tt.want {
+				t.Errorf("serviceAccountSubjectMatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRBACRuleSummary(t *testing.T) {
+	rules := []interface{}{
+		map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"pods", "pods/log"}, "verbs": []interface{}{"watch", "get", "list"}},
+		map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"pods"}, "verbs": []interface{}{"escalate", "delete"}},
+		map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"secrets"}, "resourceNames": []interface{}{"b", "a"}, "verbs": []interface{}{"get"}},
+		map[string]interface{}{"apiGroups": []interface{}{"apps"}, "resources": []interface{}{"deployments"}, "verbs": []interface{}{"get", "*"}},
+		map[string]interface{}{"apiGroups": []interface{}{"*"}, "resources": []interface{}{"*"}, "verbs": []interface{}{"list"}},
+		map[string]interface{}{"nonResourceURLs": []interface{}{"/healthz"}, "verbs": []interface{}{"get"}},
+	}
+	want := []map[string]interface{}{
+		{"resource": "*", "verbs": []string{"list"}, "resourceNames": []string{}, "nonResource": false},
+		{"resource": "deployments.apps", "verbs": []string{"*"}, "resourceNames": []string{}, "nonResource": false},
+		{"resource": "pods", "verbs": []string{"get", "list", "watch", "delete", "escalate"}, "resourceNames": []string{}, "nonResource": false},
+		{"resource": "pods/log", "verbs": []string{"get", "list", "watch"}, "resourceNames": []string{}, "nonResource": false},
+		{"resource": "secrets", "verbs": []string{"get"}, "resourceNames": []string{"a", "b"}, "nonResource": false},
+		{"resource": "/healthz", "verbs": []string{"get"}, "resourceNames": []string(nil), "nonResource": true},
+	}
+	if got := rbacRuleSummary(rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("rbacRuleSummary() = %v, want %v", got, want)
+	}
+}
@@ -113,6 +113,18 @@ type renderEngine struct {
 	cfg          *RenderConfig
 	renderedUIDs uidSet
 	templateSet  *templateSet
+	// reports collects the per-object HealthReports under --output, see renderObj.
+	reports []HealthReport
+	// related, when set, is told about every object a render looks up by reference or as an
+	// owner; --tui uses it to offer those objects as links from the render, see noteRelated.
+	related func(RenderableObject)
+}
+
+// noteRelated passes r to the related hook, if one is set.
+func (e *renderEngine) noteRelated(r RenderableObject) {
+	if e.related != nil {
+		e.related(r)
+	}
 }
 
 // templateSet holds the parsed embedded and user-overlay templates as two independent
@@ -60,6 +60,43 @@ func TestFindTemplateName(t *testing.T) {
 	}
 }
 
+// TestFindTemplateNameCollidingKinds checks, against the shipped templates, that a Kind name
+// several projects use only reaches the template written for its own API group; the others fall
+// through to DefaultResource rather than rendering sections that can never apply to them.
+func TestFindTemplateNameCollidingKinds(t *testing.T) {
+	ts, err := getTemplate(NewRenderConfig(viper.New()))
+	if err != nil {
+		t.Fatalf("getTemplate() error = %v", err)
+	}
+	tests := []struct {
+		kind  string
+		group string
+		want  string
+	}{
+		{"Application", "argoproj.io", "Application.argoproj.io"},
+		{"Application", "app.k8s.io", "DefaultResource"},
+		{"Application", "core.oam.dev", "DefaultResource"},
+		{"AppProject", "argoproj.io", "AppProject.argoproj.io"},
+		{"ApplicationSet", "argoproj.io", "ApplicationSet.argoproj.io"},
+		{"ClusterPolicy", "kyverno.io", "ClusterPolicy.kyverno.io"},
+		{"ClusterPolicy", "nvidia.com", "DefaultResource"},
+		{"AuthorizationPolicy", "security.istio.io", "AuthorizationPolicy.security.istio.io"},
+		{"AuthorizationPolicy", "policy.linkerd.io", "DefaultResource"},
+		{"HelmChart", "source.toolkit.fluxcd.io", "HelmChart.source.toolkit.fluxcd.io"},
+		{"HelmChart", "helm.cattle.io", "DefaultResource"},
+		{"GitRepository", "source.toolkit.fluxcd.io", "GitRepository.source.toolkit.fluxcd.io"},
+		{"OCIRepository", "source.toolkit.fluxcd.io", "OCIRepository.source.toolkit.fluxcd.io"},
+		{"HelmRepository", "source.toolkit.fluxcd.io", "HelmRepository.source.toolkit.fluxcd.io"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+"."+tt.group, func(t *testing.T) {
+			if _, got := ts.findTemplateName(tt.kind, tt.group); got != tt.want {
+				t.Errorf("findTemplateName(%q, %q) = %q, want %q", tt.kind, tt.group, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestUserOverlayCannotHijackInternalHelper is the regression test for #809: a user overlay file
 // that redefines an internal shared partial's name (resource_ref, in this repro) must never
 // affect a built-in Kind template's use of the real helper, even though before #809 both were
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
 	"io"
 	"text/template"
@@ -9,6 +10,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
@@ -46,6 +48,9 @@ type RenderableObject struct {
 
 // KStatus return a Result object of kstatus for the object.
 func (r RenderableObject) KStatus() *kstatus.Result {
+	if r.GroupVersionKind().GroupKind() == argoCDApplicationGroupKind && r.GetDeletionTimestamp() == nil {
+		return argoCDApplicationKStatus(r)
+	}
 	result, err := kstatus.Compute(&r.Unstructured)
 	if err != nil {
 		klog.V(2).ErrorS(err, "kstatus.Compute failed", "r", r)
@@ -53,6 +58,33 @@ func (r RenderableObject) KStatus() *kstatus.Result {
 	return result
 }
 
+var argoCDApplicationGroupKind = schema.GroupKind{Group: "argoproj.io", Kind: "Application"}
+
+// argoCDApplicationKStatus is KStatus for an Argo CD Application. Its conditions carry no status
+// and none of them is Ready, so kstatus would call every Application Current; the verdict is in
+// status.health.status and status.sync.status instead, read the way argocd_health_status and
+// argocd_sync_status color them: Degraded or Missing health and an Unknown sync (the comparison
+// itself failed) are failures, anything short of Healthy and Synced is still in progress.
+func argoCDApplicationKStatus(r RenderableObject) *kstatus.Result {
+	health, _, _ := unstructured.NestedString(r.Object, "status", "health", "status")
+	healthMessage, _, _ := unstructured.NestedString(r.Object, "status", "health", "message")
+	syncStatus, _, _ := unstructured.NestedString(r.Object, "status", "sync", "status")
+	result := &kstatus.Result{
+		Status:  kstatus.InProgressStatus,
+		Message: fmt.Sprintf("Sync: %s, Health: %s", cmp.Or(syncStatus, "Unknown"), cmp.Or(health, "Unknown")),
+	}
+	if healthMessage != "" {
+		result.Message += ": " + healthMessage
+	}
+	switch {
+	case health == "Degraded" || health == "Missing" || syncStatus == "Unknown":
+		result.Status = kstatus.FailedStatus
+	case health == "Healthy" && syncStatus == "Synced":
+		result.Status = kstatus.CurrentStatus
+	}
+	return result
+}
+
 // Problematic reports whether the object's kstatus disagrees with Current -- the same "not
 // Current" test kstatus_if_abnormal already uses in templates -- for callers that need it as a
 // plain bool rather than rendered text, e.g. deciding whether a matched Pod is worth a full
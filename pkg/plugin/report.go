@@ -0,0 +1,188 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/yaml"
+)
+
+// HealthReportList is the document `-o json`/`-o yaml` prints: one HealthReport per object
+// that would otherwise have been rendered, in the same order. It is always a list, even for a
+// single object, so a consumer never has to branch on the shape.
+type HealthReportList struct {
+	Items []HealthReport `json:"items"`
+}
+
+// HealthReport is the machine-readable form of the judgement the templates render for one
+// object: its kstatus verdict plus the evidence behind it. It deliberately carries only derived
+// health, not the object itself -- `kubectl get -o json` already covers that.
+type HealthReport struct {
+	ObjectRef `json:",inline"`
+	// Status and Message are kstatus' verdict, e.g. "InProgress" and "Replicas: 0/3".
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// UnhealthyConditions are the status.conditions the text view paints red or yellow, i.e.
+	// those RenderableObject.StatusConditionHealthy doesn't consider healthy.
+	UnhealthyConditions []ReportCondition `json:"unhealthyConditions,omitempty"`
+	WarningEvents       []ReportEvent     `json:"warningEvents,omitempty"`
+	// Owners is the ownerReferences chain, nearest owner first, each with its own kstatus.
+	Owners []OwnerRef `json:"owners,omitempty"`
+	// OrphanedOwners are ownerReferences (of this object or any owner above it) whose owner no
+	// longer exists.
+	OrphanedOwners []ObjectRef `json:"orphanedOwners,omitempty"`
+}
+
+// ObjectRef identifies an object in a HealthReport.
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// OwnerRef is one step of a HealthReport's owner chain, with the owner's own kstatus.
+type OwnerRef struct {
+	ObjectRef `json:",inline"`
+	Status    string `json:"status"`
+}
+
+type ReportCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+type ReportEvent struct {
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Count    int32  `json:"count,omitempty"`
+	LastSeen string `json:"lastSeen,omitempty"`
+}
+
+// reportOutput is the --output format, empty for the default human-readable text.
+func (e *renderEngine) reportOutput() string {
+	return e.cfg.Viper.GetString("output")
+}
+
+// newHealthReport builds r's HealthReport. Events and owners come from the same live lookups
+// the templates use, so --local and --shallow leave them empty for the same reason they leave
+// the text view's events/owners sections empty.
+func newHealthReport(r RenderableObject) HealthReport {
+	report := HealthReport{
+		ObjectRef: objectRef(r),
+		Status:    string(kstatus.UnknownStatus),
+	}
+	if result := r.KStatus(); result != nil {
+		report.Status = string(result.Status)
+		report.Message = result.Message
+	}
+	for _, c := range r.StatusConditions() {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] == nil || r.StatusConditionHealthy(condition) {
+			continue
+		}
+		report.UnhealthyConditions = append(report.UnhealthyConditions, ReportCondition{
+			Type:               fmt.Sprint(condition["type"]),
+			Status:             fmt.Sprint(condition["status"]),
+			Reason:             stringOrEmpty(condition["reason"]),
+			Message:            stringOrEmpty(condition["message"]),
+			LastTransitionTime: stringOrEmpty(condition["lastTransitionTime"]),
+		})
+	}
+	report.WarningEvents = warningEvents(r)
+	report.Owners, report.OrphanedOwners = ownerChain(r, map[types.UID]bool{r.GetUID(): true})
+	return report
+}
+
+func objectRef(r RenderableObject) ObjectRef {
+	return ObjectRef{APIVersion: r.APIVersion(), Kind: r.Kind(), Name: r.Name(), Namespace: r.Namespace()}
+}
+
+func stringOrEmpty(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func warningEvents(r RenderableObject) (out []ReportEvent) {
+	if r.LiveQueriesDisabled() {
+		return
+	}
+	eventList, err := r.repo.ObjectEvents(&r.Unstructured)
+	if err != nil {
+		klog.V(3).ErrorS(err, "error getting events", "r", r)
+		return
+	}
+	for _, event := range eventList.Items {
+		if event.Type != "Warning" {
+			continue
+		}
+		reportEvent := ReportEvent{Reason: event.Reason, Message: event.Message, Count: event.Count}
+		lastSeen := event.LastTimestamp.Time
+		if lastSeen.IsZero() {
+			lastSeen = event.EventTime.Time
+		}
+		if !lastSeen.IsZero() {
+			reportEvent.LastSeen = lastSeen.UTC().Format(time.RFC3339)
+		}
+		out = append(out, reportEvent)
+	}
+	return
+}
+
+// ownerChain walks KubeGetOwners upwards, depth first. seen guards against ownerReference
+// cycles, which the apiserver doesn't prevent.
+func ownerChain(r RenderableObject, seen map[types.UID]bool) (owners []OwnerRef, orphans []ObjectRef) {
+	result := r.KubeGetOwners()
+	for _, orphan := range result.Orphans {
+		orphans = append(orphans, ObjectRef{APIVersion: orphan.APIVersion, Kind: orphan.Kind, Name: orphan.Name, Namespace: r.Namespace()})
+	}
+	for _, owner := range result.Owners {
+		if seen[owner.GetUID()] {
+			continue
+		}
+		seen[owner.GetUID()] = true
+		ref := OwnerRef{ObjectRef: objectRef(owner), Status: string(kstatus.UnknownStatus)}
+		if ownerStatus := owner.KStatus(); ownerStatus != nil {
+			ref.Status = string(ownerStatus.Status)
+		}
+		owners = append(owners, ref)
+		moreOwners, moreOrphans := ownerChain(owner, seen)
+		owners = append(owners, moreOwners...)
+		orphans = append(orphans, moreOrphans...)
+	}
+	return owners, orphans
+}
+
+// writeReports prints every collected HealthReport as one document in the --output format.
+func writeReports(wr io.Writer, format string, reports []HealthReport) error {
+	list := HealthReportList{Items: reports}
+	if list.Items == nil {
+		list.Items = []HealthReport{}
+	}
+	var out []byte
+	var err error
+	switch format {
+	case "json":
+		out, err = json.MarshalIndent(list, "", "    ")
+		out = append(out, '\n')
+	case "yaml":
+		out, err = yaml.Marshal(list)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = wr.Write(out)
+	return err
+}
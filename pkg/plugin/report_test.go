@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// TestNewHealthReportOwnerChain checks the owner chain walks past the first owner (Pod ->
+// ReplicaSet -> Deployment), carries each owner's own kstatus, and lists dangling
+// ownerReferences separately.
+func TestNewHealthReportOwnerChain(t *testing.T) {
+	f := cmdtesting.NewTestFactory().WithNamespace("test")
+	f.Client = &fake.RESTClient{}
+	f.UnstructuredClient = f.Client
+	t.Cleanup(func() { f.Cleanup() })
+	dynClient, err := f.DynamicClient()
+	require.NoError(t, err)
+	for _, obj := range []*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "ReplicaSet",
+			"metadata": map[string]interface{}{
+				"name": "web-1", "namespace": "test", "uid": "rs-uid",
+				"ownerReferences": []interface{}{
+					map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web", "uid": "deploy-uid"},
+				},
+			},
+		}},
+		{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": "test", "uid": "deploy-uid"},
+		}},
+	} {
+		gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: map[string]string{"ReplicaSet": "replicasets", "Deployment": "deployments"}[obj.GetKind()]}
+		_, err := dynClient.Resource(gvr).Namespace("test").Create(context.TODO(), obj, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	cfg := NewRenderConfig(viper.New())
+	repo, err := input.NewResourceRepo(f, cfg.Viper)
+	require.NoError(t, err)
+	e, err := newRenderEngine(genericiooptions.NewTestIOStreamsDiscard(), cfg)
+	require.NoError(t, err)
+	pod := newRenderableObject(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name": "web-1-abc", "namespace": "test", "uid": "pod-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web-1", "uid": "rs-uid"},
+				map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "gone", "uid": "gone-uid"},
+			},
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "ContainersNotReady"},
+				map[string]interface{}{"type": "PodScheduled", "status": "True"},
+			},
+		},
+	}, e, repo)
+
+	report := newHealthReport(pod)
+
+	assert.Equal(t, ObjectRef{APIVersion: "v1", Kind: "Pod", Name: "web-1-abc", Namespace: "test"}, report.ObjectRef)
+	assert.Equal(t, []ReportCondition{{Type: "Ready", Status: "False", Reason: "ContainersNotReady"}}, report.UnhealthyConditions)
+	require.Len(t, report.Owners, 2)
+	assert.Equal(t, "ReplicaSet", report.Owners[0].Kind)
+	assert.Equal(t, "Deployment", report.Owners[1].Kind)
+	assert.NotEmpty(t, report.Owners[1].Status)
+	assert.Equal(t, []ObjectRef{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "gone", Namespace: "test"}}, report.OrphanedOwners)
+}
+
+func TestWriteReports(t *testing.T) {
+	reports := []HealthReport{{
+		ObjectRef: ObjectRef{APIVersion: "v1", Kind: "Pod", Name: "p"},
+		Status:    "Current",
+	}}
+	var out bytes.Buffer
+	require.NoError(t, writeReports(&out, "yaml", reports))
+	assert.Equal(t, "items:\n- apiVersion: v1\n  kind: Pod\n  name: p\n  status: Current\n", out.String())
+
+	out.Reset()
+	require.NoError(t, writeReports(&out, "json", nil))
+	assert.Equal(t, "{\n    \"items\": []\n}\n", out.String())
+
+	assert.Error(t, writeReports(&out, "wide", reports))
+}
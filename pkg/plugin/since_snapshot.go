@@ -0,0 +1,240 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// statusDigest is an object's derived status -- what a render of it would flag, rather than its
+// raw spec -- as "key: value" lines in a stable order, so two digests of the same object taken
+// at different times diff the way KubeGetUnifiedDiffString diffs two revisions of its spec.
+type statusDigest struct {
+	lines []string
+	// events are kept apart, by Event name: the API server expires Events after an hour, so
+	// an Event that only the older digest has is left out rather than reported as gone.
+	events map[string]string
+}
+
+// newStatusDigest derives r's digest: kstatus, every condition's status and reason, the
+// replica-style counters of its status, the restart counts of its Pods (itself, for a Pod) and
+// its Events.
+func newStatusDigest(r RenderableObject) statusDigest {
+	d := statusDigest{events: map[string]string{}}
+	if result := r.KStatus(); result != nil {
+		d.lines = append(d.lines, fmt.Sprintf("kstatus: %s", result.Status))
+		if result.Message != "" {
+			d.lines = append(d.lines, fmt.Sprintf("kstatus message: %s", result.Message))
+		}
+	}
+	var conditions []string
+	for _, item := range r.StatusConditions() {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] == nil {
+			continue
+		}
+		line := fmt.Sprintf("condition %s: %s", condition["type"], condition["status"])
+		if reason := stringOrEmpty(condition["reason"]); reason != "" {
+			line += fmt.Sprintf(" (%s)", reason)
+		}
+		conditions = append(conditions, line)
+	}
+	sort.Strings(conditions)
+	d.lines = append(d.lines, conditions...)
+	if replicas, found, _ := unstructured.NestedInt64(r.Object, "spec", "replicas"); found {
+		d.lines = append(d.lines, fmt.Sprintf("spec.replicas: %d", replicas))
+	}
+	status, _, _ := unstructured.NestedMap(r.Object, "status")
+	var counters []string
+	for key, value := range status {
+		if key == "observedGeneration" {
+			continue
+		}
+		if count, ok := value.(int64); ok {
+			counters = append(counters, fmt.Sprintf("status.%s: %d", key, count))
+		}
+	}
+	sort.Strings(counters)
+	d.lines = append(d.lines, counters...)
+	d.lines = append(d.lines, digestRestarts(r)...)
+	if !r.LiveQueriesDisabled() {
+		eventList, err := r.repo.ObjectEvents(&r.Unstructured)
+		if err != nil {
+			klog.V(3).ErrorS(err, "error getting events", "r", r)
+		} else {
+			for _, event := range eventList.Items {
+				d.events[event.Name] = fmt.Sprintf("event %s %s (x%d): %s", event.Type, event.Reason, max(event.Count, 1), event.Message)
+			}
+		}
+	}
+	return d
+}
+
+// digestPods returns the Pods whose restarts count toward r's: r itself for a Pod, and otherwise
+// the Pods its selector (or, for a Job, its job-name label) picks -- looked up exactly the way
+// the workload templates do, so a --snapshot-out bundle of a render has the answer recorded.
+func digestPods(r RenderableObject) []RenderableObject {
+	switch r.Kind() {
+	case "Pod":
+		return []RenderableObject{r}
+	case "Job":
+		return r.KubeGetByLabelsMap(r.Namespace(), "pods", map[string]interface{}{"job-name": r.Name()})
+	}
+	matchLabels, _, _ := unstructured.NestedMap(r.Object, "spec", "selector", "matchLabels")
+	if len(matchLabels) == 0 {
+		return nil
+	}
+	return r.KubeGetByLabelsMap(r.Namespace(), "pods", matchLabels)
+}
+
+func digestRestarts(r RenderableObject) (out []string) {
+	for _, pod := range digestPods(r) {
+		for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+			statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", field)
+			for _, item := range statuses {
+				containerStatus, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if restarts, _, _ := unstructured.NestedInt64(containerStatus, "restartCount"); restarts > 0 {
+					out = append(out, fmt.Sprintf("restarts %s/%s: %d", pod.Name(), containerStatus["name"], restarts))
+				}
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// text returns the digest's lines, followed by the events keep reports true for.
+func (d statusDigest) text(keep func(name string) bool) []string {
+	names := make([]string, 0, len(d.events))
+	for name := range d.events {
+		if keep(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	lines := append([]string{}, d.lines...)
+	for _, name := range names {
+		lines = append(lines, d.events[name])
+	}
+	return lines
+}
+
+// diffStatusDigests returns the unified diff from before to after, with the same headers
+// KubeGetUnifiedDiffString uses; it is empty when nothing changed.
+func diffStatusDigests(before, after statusDigest, fromFile, fromDate, toFile, toDate string) string {
+	all := func(string) bool { return true }
+	stillReported := func(name string) bool { _, ok := after.events[name]; return ok }
+	diff := difflib.UnifiedDiff{
+		A:        withNewlines(before.text(stillReported)),
+		B:        withNewlines(after.text(all)),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		FromDate: fromDate,
+		ToDate:   toDate,
+		Context:  0,
+	}
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		klog.V(3).ErrorS(err, "failed to diff status digests")
+	}
+	return text
+}
+
+func withNewlines(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = line + "\n"
+	}
+	return out
+}
+
+// printColoredDiff prints a unified diff with its removed lines red and its added ones green.
+func printColoredDiff(out io.Writer, diff string) {
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "@@"):
+			_, _ = color.New(color.Faint).Fprint(out, "  "+line)
+		case strings.HasPrefix(line, "-"):
+			_, _ = color.New(color.FgRed).Fprint(out, "  "+line)
+		case strings.HasPrefix(line, "+"):
+			_, _ = color.New(color.FgGreen).Fprint(out, "  "+line)
+		case line != "":
+			_, _ = fmt.Fprint(out, "  "+line)
+		}
+	}
+}
+
+// runSinceSnapshot is --since-snapshot: the query runs twice, against the cluster (or the
+// --snapshot-in bundle) and against the baseline bundle, and each object's two status digests
+// are diffed instead of rendering it -- how a resource degraded (or recovered) between the two,
+// for a post-incident review. Objects only one side has are reported as appeared or gone.
+func runSinceSnapshot(args []string, engine *renderEngine, repo *input.ResourceRepo, cfg *RenderConfig) error {
+	baseline, err := input.NewResourceRepo(cfg.Baseline, cfg.Viper)
+	if err != nil {
+		klog.V(2).ErrorS(err, "Error creating the baseline repo")
+		return err
+	}
+	before, err := listObjects(baseline.CLIQueryResults(args), engine, baseline)
+	if err != nil {
+		klog.V(1).ErrorS(err, "Error querying the baseline snapshot")
+		return fmt.Errorf("querying --since-snapshot: %w", err)
+	}
+	after, err := listObjects(repo.CLIQueryResults(args), engine, repo)
+	if err != nil {
+		klog.V(1).ErrorS(err, "Error querying resources")
+		return err
+	}
+	if len(before) == 0 && len(after) == 0 {
+		return fmt.Errorf("no resources found")
+	}
+	// The same lookups the recorded render made are the ones the bundle can answer.
+	prefetchRelated(before, engine, baseline)
+	prefetchRelated(after, engine, repo)
+
+	out := engine.ioStreams.Out
+	bold := color.New(color.Bold)
+	fromDate := fmt.Sprintf("%s (%s ago)", cfg.BaselineCapturedAt.String(), cfg.ago(cfg.BaselineCapturedAt))
+	toDate := cfg.Now().UTC().String()
+	beforeByKey := map[string]RenderableObject{}
+	for _, r := range before {
+		beforeByKey[objectKey(r)] = r
+	}
+	for i, r := range after {
+		if i > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		_, _ = bold.Fprintln(out, r.String())
+		old, ok := beforeByKey[objectKey(r)]
+		if !ok {
+			_, _ = color.New(color.FgGreen).Fprintln(out, "  not in the snapshot, created since")
+			continue
+		}
+		delete(beforeByKey, objectKey(r))
+		diff := diffStatusDigests(newStatusDigest(old), newStatusDigest(r), "a "+r.String(), fromDate, "b "+r.String(), toDate)
+		if diff == "" {
+			_, _ = fmt.Fprintln(out, "  no change in health since the snapshot")
+			continue
+		}
+		printColoredDiff(out, diff)
+	}
+	for _, r := range before {
+		if _, gone := beforeByKey[objectKey(r)]; !gone {
+			continue
+		}
+		_, _ = fmt.Fprintln(out)
+		_, _ = bold.Fprintln(out, r.String())
+		_, _ = color.New(color.FgRed).Fprintln(out, "  in the snapshot, deleted since")
+	}
+	return nil
+}
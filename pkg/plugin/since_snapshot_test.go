@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffStatusDigests(t *testing.T) {
+	before := statusDigest{
+		lines: []string{"kstatus: Current", "condition Available: True (MinimumReplicasAvailable)", "status.readyReplicas: 3"},
+		events: map[string]string{
+			"web.1": "event Normal ScalingReplicaSet (x1): Scaled up replica set web-1 to 3",
+			"web.0": "event Normal ScalingReplicaSet (x1): expired since",
+		},
+	}
+	after := statusDigest{
+		lines: []string{"kstatus: InProgress", "condition Available: False (MinimumReplicasUnavailable)", "status.readyReplicas: 1"},
+		events: map[string]string{
+			"web.1": "event Normal ScalingReplicaSet (x1): Scaled up replica set web-1 to 3",
+			"web.2": "event Warning FailedCreate (x4): quota exceeded",
+		},
+	}
+	diff := diffStatusDigests(before, after, "a", "then", "b", "now")
+	assert.Equal(t, `--- a	then
++++ b	now
+@@ -1,3 +1,3 @@
+-kstatus: Current
+-condition Available: True (MinimumReplicasAvailable)
+-status.readyReplicas: 3
++kstatus: InProgress
++condition Available: False (MinimumReplicasUnavailable)
++status.readyReplicas: 1
+@@ -4,0 +5 @@
++event Warning FailedCreate (x4): quota exceeded
+`, diff, "an event that has since expired isn't reported as gone")
+
+	assert.Empty(t, diffStatusDigests(after, after, "a", "then", "b", "now"))
+}
+
+func TestDigestRestarts(t *testing.T) {
+	pod := newTestRenderableObject(map[string]interface{}{
+		"kind":     "Pod",
+		"metadata": map[string]interface{}{"name": "web-0"},
+		"status": map[string]interface{}{
+			"initContainerStatuses": []interface{}{map[string]interface{}{"name": "init", "restartCount": int64(0)}},
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "restartCount": int64(3)},
+				map[string]interface{}{"name": "sidecar", "restartCount": int64(1)},
+			},
+		},
+	})
+	assert.Equal(t, []string{"restarts web-0/app: 3", "restarts web-0/sidecar: 1"}, digestRestarts(pod))
+}
@@ -5,6 +5,7 @@ package plugin
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -65,6 +66,8 @@ func (r RenderableObject) KubeGetFirst(namespace string, args ...string) Rendera
 	if err != nil {
 		klog.V(3).ErrorS(err, "KubeGetFirst failed",
 			"namespace", namespace, "args", args)
+	} else {
+		r.engine.noteRelated(nr)
 	}
 	return nr
 }
@@ -82,6 +85,9 @@ func (r RenderableObject) KubeGetByLabelsMap(namespace, resourceType string, lab
 	for k, v := range labels {
 		labelPairs = append(labelPairs, fmt.Sprintf("%s=%s", k, v))
 	}
+	// Map order is random: sorted, the same labels always make the same request, so they hit
+	// the repo's cache and a --snapshot-in replay finds what --snapshot-out recorded.
+	sort.Strings(labelPairs)
 	selector := strings.Join(labelPairs, ",")
 	unstructuredObjects, err := r.repo.Objects(namespace, []string{resourceType}, selector)
 	if err != nil {
@@ -127,6 +133,9 @@ func (r RenderableObject) KubeGetOwners() (out OwnersResult) {
 		klog.V(3).ErrorS(err, "error getting owners", "r", r)
 	}
 	out.Owners = r.objectsToRenderableObjects(owners)
+	for _, owner := range out.Owners {
+		r.engine.noteRelated(owner)
+	}
 	out.Orphans = orphans
 	return out
 }
@@ -542,6 +551,237 @@ func (r RenderableObject) KubeGetCalicoGlobalNetworkPoliciesMatchingPod(namespac
 	return out
 }
 
+// KubeGetNetworkPolicyPeerPods returns the Pods a NetworkPolicy peer (a from/to entry, or
+// dict "podSelector" spec.podSelector for the policy itself) in namespace currently covers -- the
+// opposite direction of KubeGetNetworkPoliciesMatchingPod. See policyPeerPods for the result.
+func (r RenderableObject) KubeGetNetworkPolicyPeerPods(namespace string, peer map[string]interface{}) map[string]interface{} {
+	if r.LiveQueriesDisabled() {
+		return nil
+	}
+	klog.V(5).InfoS("called KubeGetNetworkPolicyPeerPods", "r", r, "namespace", namespace, "peer", peer)
+	return r.policyPeerPods(networkPolicyPeerSelector(namespace, peer))
+}
+
+// KubeGetCiliumEndpointSelectorPods returns the Pods a Cilium endpointSelector (a Rule's own, or
+// a fromEndpoints/toEndpoints entry) in a policy in namespace -- "" for a
+// CiliumClusterwideNetworkPolicy -- currently covers. See ciliumEndpointSelector for how Cilium's
+// identity labels are matched, and policyPeerPods for the result.
+func (r RenderableObject) KubeGetCiliumEndpointSelectorPods(namespace string, endpointSelector map[string]interface{}) map[string]interface{} {
+	if r.LiveQueriesDisabled() {
+		return nil
+	}
+	klog.V(5).InfoS("called KubeGetCiliumEndpointSelectorPods", "r", r, "namespace", namespace, "endpointSelector", endpointSelector)
+	return r.policyPeerPods(ciliumEndpointSelector(namespace, endpointSelector))
+}
+
+// KubeGetCalicoSelectorPods returns the Pods a Calico policy's spec, or one of its rules'
+// source/destination, in namespace -- "" for a GlobalNetworkPolicy -- currently covers, going by
+// its selector, notSelector and namespaceSelector. See calicoEntitySelector, and policyPeerPods
+// for the result.
+func (r RenderableObject) KubeGetCalicoSelectorPods(namespace string, entity map[string]interface{}) map[string]interface{} {
+	if r.LiveQueriesDisabled() {
+		return nil
+	}
+	klog.V(5).InfoS("called KubeGetCalicoSelectorPods", "r", r, "namespace", namespace, "entity", entity)
+	return r.policyPeerPods(calicoEntitySelector(namespace, entity))
+}
+
+// KubeGetClusterRolesMatchingSelector returns the ClusterRoles whose labels match one of an
+// aggregated ClusterRole's aggregationRule.clusterRoleSelectors -- the roles the
+// clusterrole-aggregation controller copies rules from. A selector matching none leaves the
+// aggregated role without whatever it was meant to pick up, and nothing reports it.
+func (r RenderableObject) KubeGetClusterRolesMatchingSelector(selector map[string]interface{}) (out []RenderableObject) {
+	out = make([]RenderableObject, 0)
+	if r.LiveQueriesDisabled() {
+		return
+	}
+	klog.V(5).InfoS("called KubeGetClusterRolesMatchingSelector", "r", r, "selector", selector)
+	sel, err := labelSelectorFromMap(selector)
+	if err != nil {
+		klog.V(3).ErrorS(err, "invalid clusterRoleSelector", "r", r, "selector", selector)
+		return
+	}
+	clusterRoles, err := r.repo.Objects("", []string{"clusterroles"}, "")
+	if err != nil {
+		klog.V(3).ErrorS(err, "error listing clusterroles", "r", r)
+		return
+	}
+	for _, obj := range clusterRoles {
+		clusterRole := r.newRenderableObject(obj)
+		if sel.Matches(labels.Set(stringifyLabels(clusterRole.Labels()))) {
+			out = append(out, clusterRole)
+		}
+	}
+	return out
+}
+
+// KubeGetPrometheusOperatorSelected lists the objects of kind (ServiceMonitor, PodMonitor, Probe,
+// ScrapeConfig, PrometheusRule or AlertmanagerConfig) this Prometheus, PrometheusAgent,
+// ThanosRuler or Alertmanager picks up through its own selector pair -- what the operator renders
+// into its configuration. Returns {"objects": []RenderableObject, "namespaces": []string} (the
+// distinct namespaces they live in), {"error": string} when the selectors can't be evaluated or
+// a listing fails, and nil when live queries are off.
+func (r RenderableObject) KubeGetPrometheusOperatorSelected(kind string) map[string]interface{} {
+	if r.LiveQueriesDisabled() {
+		return nil
+	}
+	klog.V(5).InfoS("called KubeGetPrometheusOperatorSelected", "r", r, "kind", kind)
+	fields, ok := prometheusOperatorSelectorFields[kind]
+	if !ok {
+		return map[string]interface{}{"error": fmt.Sprintf("%s is not selected by the Prometheus operator", kind)}
+	}
+	spec, _ := r.Object["spec"].(map[string]interface{})
+	// A null namespace selector only ever looks in the owner's namespace, so there's no need to
+	// list every namespace's objects, nor the namespaces themselves.
+	listIn := ""
+	var namespaceLabels map[string]map[string]string
+	if spec[fields.prefix+"NamespaceSelector"] == nil {
+		listIn = r.Namespace()
+	} else {
+		var err error
+		if namespaceLabels, err = r.kubeGetNamespaceLabels(); err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+	}
+	objects, err := r.repo.Objects(listIn, []string{kind}, "")
+	if err != nil {
+		klog.V(3).ErrorS(err, "error listing selectable objects", "r", r, "kind", kind)
+		return map[string]interface{}{"error": err.Error()}
+	}
+	selected := make([]RenderableObject, 0)
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, obj := range objects {
+		candidate := r.newRenderableObject(obj)
+		match, err := prometheusOperatorSelects(r.Namespace(), spec, kind, candidate.Namespace(),
+			stringifyLabels(candidate.Labels()), namespaceLabels[candidate.Namespace()])
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		if !match {
+			continue
+		}
+		selected = append(selected, candidate)
+		if !seen[candidate.Namespace()] {
+			seen[candidate.Namespace()] = true
+			namespaces = append(namespaces, candidate.Namespace())
+		}
+	}
+	sort.Strings(namespaces)
+	return map[string]interface{}{"objects": selected, "namespaces": namespaces}
+}
+
+// KubeGetPrometheusOperatorSelectors is the reverse of KubeGetPrometheusOperatorSelected: the
+// Prometheus, PrometheusAgent, ThanosRuler or Alertmanager objects whose selectors pick up this
+// ServiceMonitor (or PodMonitor, Probe, ScrapeConfig, PrometheusRule, AlertmanagerConfig). One
+// nobody selects is never scraped or loaded, and nothing on the object itself says so.
+func (r RenderableObject) KubeGetPrometheusOperatorSelectors() (out []RenderableObject) {
+	out = make([]RenderableObject, 0)
+	if r.LiveQueriesDisabled() {
+		return
+	}
+	klog.V(5).InfoS("called KubeGetPrometheusOperatorSelectors", "r", r)
+	fields, ok := prometheusOperatorSelectorFields[r.Kind()]
+	if !ok {
+		return
+	}
+	namespaceLabels, err := r.kubeGetNamespaceLabels()
+	if err != nil {
+		return
+	}
+	for _, selectorKind := range fields.selectors {
+		// PrometheusAgent and ThanosRuler CRDs are optional; a failed listing just means none.
+		owners, err := r.repo.Objects("", []string{selectorKind}, "")
+		if err != nil {
+			klog.V(3).ErrorS(err, "error listing selecting objects", "r", r, "kind", selectorKind)
+			continue
+		}
+		for _, obj := range owners {
+			owner := r.newRenderableObject(obj)
+			spec, _ := obj["spec"].(map[string]interface{})
+			match, err := prometheusOperatorSelects(owner.Namespace(), spec, r.Kind(), r.Namespace(),
+				stringifyLabels(r.Labels()), namespaceLabels[r.Namespace()])
+			if err != nil {
+				klog.V(3).ErrorS(err, "invalid selector", "r", r, "owner", owner)
+				continue
+			}
+			if match {
+				out = append(out, owner)
+			}
+		}
+	}
+	return out
+}
+
+// kubeGetNamespaceLabels lists every namespace once for its labels, by namespace name.
+func (r RenderableObject) kubeGetNamespaceLabels() (map[string]map[string]string, error) {
+	namespaces, err := r.repo.Objects("", []string{"namespaces"}, "")
+	if err != nil {
+		klog.V(3).ErrorS(err, "error listing namespaces", "r", r)
+		return nil, err
+	}
+	out := map[string]map[string]string{}
+	for _, ns := range namespaces {
+		nsObj := r.newRenderableObject(ns)
+		out[nsObj.Name()] = stringifyLabels(nsObj.Labels())
+	}
+	return out, nil
+}
+
+// policyPeerPods lists the Pods sel currently covers, returning {"pods": []RenderableObject} plus
+// "namespaces" (the names of the namespaces it selected) when sel has a namespace selector, or
+// {"error": string} when sel can't be evaluated or a listing it depends on failed -- so a
+// template can tell "selects nothing" apart from "couldn't tell". Pods that have finished
+// (Succeeded/Failed) are left out: they have no IP left for a policy to apply to. Namespaces are
+// listed once for their labels, which Cilium selectors and namespace selectors both match on.
+func (r RenderableObject) policyPeerPods(sel policyPeerSelector) map[string]interface{} {
+	if sel.err != nil {
+		return map[string]interface{}{"error": sel.err.Error()}
+	}
+	namespaces, err := r.repo.Objects("", []string{"namespaces"}, "")
+	if err != nil {
+		klog.V(3).ErrorS(err, "error listing namespaces", "r", r)
+		if sel.namespaces != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+	}
+	namespaceLabels := map[string]map[string]string{}
+	var selectedNamespaces []string
+	for _, ns := range namespaces {
+		nsObj := r.newRenderableObject(ns)
+		namespaceLabels[nsObj.Name()] = stringifyLabels(nsObj.Labels())
+		if sel.namespaces != nil && sel.namespaces(nsObj.Name(), namespaceLabels[nsObj.Name()]) {
+			selectedNamespaces = append(selectedNamespaces, nsObj.Name())
+		}
+	}
+	listIn := []string{sel.namespace}
+	if sel.namespaces != nil {
+		listIn = selectedNamespaces
+	}
+	pods := make([]RenderableObject, 0)
+	for _, namespace := range listIn {
+		objects, err := r.repo.Objects(namespace, []string{"pods"}, "")
+		if err != nil {
+			klog.V(3).ErrorS(err, "error listing pods", "r", r, "namespace", namespace)
+			return map[string]interface{}{"error": err.Error()}
+		}
+		for _, obj := range objects {
+			pod := r.newRenderableObject(obj)
+			if phase, _, _ := unstructured.NestedString(obj, "status", "phase"); phase == "Succeeded" || phase == "Failed" {
+				continue
+			}
+			if sel.pods(stringifyLabels(pod.Labels()), pod.Namespace(), namespaceLabels[pod.Namespace()]) {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	result := map[string]interface{}{"pods": pods}
+	if sel.namespaces != nil {
+		result["namespaces"] = selectedNamespaces
+	}
+	return result
+}
+
 func doesServiceMatchLabels(svc corev1.Service, labels map[string]string) bool {
 	if svc.Spec.Type == "ExternalName" {
 		return false
@@ -604,6 +844,41 @@ func (r RenderableObject) KubeGetNodeHealthz(nodeName string) string {
 	return strings.TrimSpace(nodeHealthz)
 }
 
+// KubeGetWorkloadClusterNode returns {"node": map} for a Node in a Cluster API workload cluster,
+// or {"error": string} when that cluster can't be reached with its kubeconfig Secret. The Node is a
+// plain map rather than a RenderableObject: every live lookup a RenderableObject makes would go
+// to the management cluster, where this Node doesn't exist.
+// Connecting to another cluster with its admin credentials needs --include-workload-cluster, and
+// never happens in a --snapshot-in replay, whose Secrets come from the bundle.
+func (r RenderableObject) KubeGetWorkloadClusterNode(namespace, clusterName, nodeName string) map[string]interface{} {
+	if r.LiveQueriesDisabled() || !r.Config.GetBool("include-workload-cluster") || r.Config.GetString("snapshot-in") != "" {
+		return nil
+	}
+	klog.V(5).InfoS("called KubeGetWorkloadClusterNode", "r", r, "cluster", clusterName, "node", nodeName)
+	node, err := r.repo.WorkloadClusterNode(namespace, clusterName, nodeName)
+	if err != nil {
+		klog.V(3).ErrorS(err, "failed to get workload cluster node", "r", r, "cluster", clusterName, "node", nodeName)
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return map[string]interface{}{"node": map[string]interface{}(node)}
+}
+
+// KubeGetCNPGInstanceStatus returns {"status": map} with a CloudNativePG instance manager's own
+// status report for the named Pod, or {"error": string} when it can't be reached -- which, for the
+// primary, is itself worth showing.
+func (r RenderableObject) KubeGetCNPGInstanceStatus(namespace, podName string) map[string]interface{} {
+	if r.LiveQueriesDisabled() {
+		return nil
+	}
+	klog.V(5).InfoS("called KubeGetCNPGInstanceStatus", "r", r, "namespace", namespace, "pod", podName)
+	status, err := r.repo.CNPGInstanceStatus(namespace, podName)
+	if err != nil {
+		klog.V(3).ErrorS(err, "failed to get CloudNativePG instance status", "r", r, "namespace", namespace, "pod", podName)
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return map[string]interface{}{"status": map[string]interface{}(status)}
+}
+
 // KubeGetPodMetrics returns the PodMetrics for the named pod. It first tries a single cluster-wide
 // PodMetrics list, reused for every pod/node in the render (see AllNamespacesPodMetrics). If that's
 // not available (e.g. RBAC only allows namespace-scoped access), it falls back to fetching
@@ -771,6 +1046,13 @@ func removeFieldsThatCreateDiffNoise(obj *unstructured.Unstructured) {
 	// https://github.com/kubernetes/kubernetes/issues/47554#issuecomment-522924195
 	unstructured.RemoveNestedField(obj.Object, "metadata", "labels", "controller.kubernetes.io/hash") // StatefulSet
 	unstructured.RemoveNestedField(obj.Object, "metadata", "labels", "controller-revision-hash")      // DaemonSet
+	// Argo Rollouts stamps its own hash and revision bookkeeping onto each of a Rollout's ReplicaSets
+	unstructured.RemoveNestedField(obj.Object, "metadata", "labels", "rollouts-pod-template-hash")
+	unstructured.RemoveNestedField(obj.Object, "spec", "selector", "matchLabels", "rollouts-pod-template-hash")
+	unstructured.RemoveNestedField(obj.Object, "spec", "template", "metadata", "labels", "rollouts-pod-template-hash")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "rollout.argoproj.io/revision")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "rollout.argoproj.io/desired-replicas")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "scale-down-deadline")
 
 	unstructured.RemoveNestedField(obj.Object, "status")
 }
@@ -779,6 +1061,9 @@ func removeFieldsThatCreateDiffNoise(obj *unstructured.Unstructured) {
 func (r RenderableObject) RolloutStatus(obj RenderableObject) map[string]interface{} {
 	klog.V(5).InfoS("called RolloutStatus", "r", r, "obj", obj)
 	groupKind := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if groupKind == argoRolloutGroupKind {
+		return argoRolloutStatus(obj)
+	}
 	statusViewer, err := polymorphichelpers.StatusViewerFor(groupKind)
 	if err != nil {
 		klog.V(3).ErrorS(err, "cant get RolloutStatus for kind", "r", r, "obj", obj, "groupKind", groupKind)
@@ -798,6 +1083,39 @@ func (r RenderableObject) RolloutStatus(obj RenderableObject) map[string]interfa
 	}
 }
 
+var argoRolloutGroupKind = schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}
+
+// argoRolloutStatus is RolloutStatus for an Argo Rollouts Rollout, which kubectl's status viewers
+// don't know. The rollouts controller already sums its progress up in status.phase and
+// status.message -- the same two fields `kubectl argo rollouts status` reports -- so done means
+// Healthy, Degraded (an aborted or failed update) is the error, and Progressing/Paused carry their
+// message. A spec change the controller hasn't observed yet isn't done whatever the phase says;
+// status.observedGeneration is a string in this API, hence the Sprint.
+func argoRolloutStatus(obj RenderableObject) map[string]interface{} {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+	observedGeneration, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "status", "observedGeneration")
+	status := map[string]interface{}{"done": false, "message": "", "error": ""}
+	switch {
+	case found && fmt.Sprint(observedGeneration) != fmt.Sprint(obj.GetGeneration()):
+		status["message"] = "waiting for rollout spec update to be observed"
+	case phase == "Healthy":
+		status["done"] = true
+	case phase == "Degraded":
+		status["error"] = argoRolloutPhaseMessage(phase, message)
+	case phase == "":
+		status["message"] = "waiting for the rollouts controller to report a phase"
+	default:
+		status["message"] = argoRolloutPhaseMessage(phase, message)
+	}
+	return status
+}
+
+// argoRolloutPhaseMessage is "<phase>: <message>", or just the phase when there is no message.
+func argoRolloutPhaseMessage(phase, message string) string {
+	return strings.TrimSuffix(strings.TrimSpace(fmt.Sprintf("%s: %s", phase, message)), ":")
+}
+
 // StatefulSetRollbackTrap detects the kubernetes/kubernetes#67250 StatefulSet RollingUpdate
 // recovery trap, documented upstream as "Forced rollback"
 // (https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#forced-rollback): with
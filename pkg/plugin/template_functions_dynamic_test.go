@@ -397,3 +397,65 @@ func TestStatefulSetPodUnreadySince(t *testing.T) {
 		})
 	}
 }
+
+func TestArgoRolloutStatus(t *testing.T) {
+	rollout := func(generation int64, status map[string]interface{}) RenderableObject {
+		return RenderableObject{Unstructured: unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Rollout",
+			"metadata":   map[string]interface{}{"name": "checkout", "generation": generation},
+			"status":     status,
+		}}}
+	}
+	tests := []struct {
+		name        string
+		obj         RenderableObject
+		wantDone    bool
+		wantMessage string
+		wantError   string
+	}{
+		{
+			name:     "healthy is done",
+			obj:      rollout(3, map[string]interface{}{"observedGeneration": "3", "phase": "Healthy"}),
+			wantDone: true,
+		},
+		{
+			name:        "string observedGeneration behind generation",
+			obj:         rollout(4, map[string]interface{}{"observedGeneration": "3", "phase": "Healthy"}),
+			wantMessage: "waiting for rollout spec update to be observed",
+		},
+		{
+			name:        "paused canary step is ongoing",
+			obj:         rollout(3, map[string]interface{}{"observedGeneration": "3", "phase": "Paused", "message": "CanaryPauseStep"}),
+			wantMessage: "Paused: CanaryPauseStep",
+		},
+		{
+			name:        "progressing without a message",
+			obj:         rollout(3, map[string]interface{}{"observedGeneration": "3", "phase": "Progressing"}),
+			wantMessage: "Progressing",
+		},
+		{
+			name:      "degraded is an error",
+			obj:       rollout(3, map[string]interface{}{"observedGeneration": "3", "phase": "Degraded", "message": "RolloutAborted: metric failed"}),
+			wantError: "Degraded: RolloutAborted: metric failed",
+		},
+		{
+			name:      "degraded without a message",
+			obj:       rollout(3, map[string]interface{}{"observedGeneration": "3", "phase": "Degraded"}),
+			wantError: "Degraded",
+		},
+		{
+			name:        "no phase reported yet",
+			obj:         rollout(1, map[string]interface{}{}),
+			wantMessage: "waiting for the rollouts controller to report a phase",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.obj.RolloutStatus(tt.obj)
+			if got["done"] != tt.wantDone || got["message"] != tt.wantMessage || got["error"] != tt.wantError {
+				t.Errorf("RolloutStatus() = %v, want done:%v message:%q error:%q", got, tt.wantDone, tt.wantMessage, tt.wantError)
+			}
+		})
+	}
+}
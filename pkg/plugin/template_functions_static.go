@@ -7,6 +7,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/util"
 )
 
 // RenderConfig carries the per-invocation configuration and time/duration hooks that template
@@ -26,6 +27,11 @@ type RenderConfig struct {
 	// deterministic rendering) and left overridable here so e2e tests don't need to wait out the
 	// real default.
 	StatefulSetRollbackTrapThreshold time.Duration
+	// Baseline, when set, reads the bundle --since-snapshot names, recorded at
+	// BaselineCapturedAt: Run then prints how each object's derived status changed since,
+	// instead of rendering it (see runSinceSnapshot).
+	Baseline           util.Factory
+	BaselineCapturedAt time.Time
 }
 
 // NewRenderConfig builds a RenderConfig backed by v, with the real Now/DurationRound/
@@ -116,8 +122,10 @@ func (cfg *RenderConfig) funcMap() template.FuncMap {
 		"networkPolicyPolicyTypes":        networkPolicyPolicyTypes,
 		"calicoPolicyTypes":               calicoPolicyTypes,
 		"ciliumPolicyDirections":          ciliumPolicyDirectionsForTemplate,
+		"ciliumLabelSelector":             ciliumLabelSelector,
 		"cronNextTime":                    cfg.cronNextTime,
 		"withinLastHour":                  cfg.withinLastHour,
+		"isPast":                          cfg.isPast,
 		"parseTLSSecretCertificate":       cfg.parseTLSSecretCertificate,
 		"qualifyKind":                     qualifyKind,
 		"hostnameIntersections":           hostnameIntersections,
@@ -130,10 +138,15 @@ func (cfg *RenderConfig) funcMap() template.FuncMap {
 		"parseBasicAuthSecret":            parseBasicAuthSecret,
 		"parseSSHAuthSecret":              parseSSHAuthSecret,
 		"parseServiceAccountTokenSecret":  parseServiceAccountTokenSecret,
+		"serviceAccountSubjectMatch":      serviceAccountSubjectMatch,
+		"rbacRuleSummary":                 rbacRuleSummary,
 		"parseBootstrapTokenSecret":       cfg.parseBootstrapTokenSecret,
 		"parseHelmReleaseSecret":          parseHelmReleaseSecret,
 		"helmReleaseManifestResources":    helmReleaseManifestResources,
 		"secretDataKeys":                  secretDataKeys,
+		"cnpgStandbys":                    cnpgStandbys,
+		"kedaTriggerStates":               kedaTriggerStates,
+		"kyvernoPolicyReportRollup":       kyvernoPolicyReportRollup,
 		"crossplaneManagedResourceDrift":  crossplaneManagedResourceDrift,
 		"crossplaneDriftLabel":            crossplaneDriftLabel,
 		"renderGroupedTable":              renderGroupedTable,
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -2040,3 +2042,435 @@ func TestQuotaHeadroomTemplateSilentWhenQuotaHasRoom(t *testing.T) {
 		t.Errorf("quota_headroom got = %q, want nothing when the quota has room", got)
 	}
 }
+
+// testEngine renders objects whose live lookups are answered by newTestEngineWithResponses.
+type testEngine struct {
+	engine *renderEngine
+	repo   *input.ResourceRepo
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newTestEngineWithResponses returns a testEngine whose every API request is answered with
+// responses["<path>"], the path being the part below the API group version
+// ("/namespaces/shop/pods"); anything else gets a 404. Every request is recorded as
+// "<path>?<query>" in requests.
+func newTestEngineWithResponses(t *testing.T, namespace string, responses map[string]string) *testEngine {
+	t.Helper()
+	te := &testEngine{}
+	f := cmdtesting.NewTestFactory().WithNamespace(namespace)
+	f.Client = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			// The typed clientset's requests carry the core group's prefix; the resource builder's don't.
+			path := strings.TrimPrefix(req.URL.Path, "/api/v1")
+			te.mu.Lock()
+			te.requests = append(te.requests, path+"?"+req.URL.RawQuery)
+			te.mu.Unlock()
+			body, ok := responses[path]
+			if !ok {
+				return &http.Response{StatusCode: http.StatusNotFound, Header: cmdtesting.DefaultHeader(), Body: io.NopCloser(strings.NewReader(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: io.NopCloser(strings.NewReader(body))}, nil
+		}),
+	}
+	f.UnstructuredClient = f.Client
+	t.Cleanup(func() { f.Cleanup() })
+	cfg := NewRenderConfig(viper.New())
+	ts, _ := getTemplate(cfg)
+	repo, err := input.NewResourceRepo(f, cfg.Viper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _ := newRenderEngine(genericiooptions.NewTestIOStreamsDiscard(), cfg)
+	e.templateSet = ts
+	te.engine, te.repo = e, repo
+	return te
+}
+
+func (te *testEngine) newObject(obj map[string]interface{}) RenderableObject {
+	return newRenderableObject(obj, te.engine, te.repo)
+}
+
+// TestArgoCDManagedResourcesTemplate covers the live-query half of argocd_managed_resources,
+// which every offline artifact under tests/artifacts/argocd-* leaves untouched: an entry Argo CD
+// reports as unhealthy gets the object's own health summary nested under it, but only when the
+// Application deploys to this cluster -- for a remote destination a lookup here would find a
+// different object, or none.
+func TestArgoCDManagedResourcesTemplate(t *testing.T) {
+	application := func(destination map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": "argocd"},
+			"spec":       map[string]interface{}{"destination": destination},
+			"status": map[string]interface{}{
+				"resources": []interface{}{
+					map[string]interface{}{"kind": "Service", "name": "web", "namespace": "test", "status": "Synced",
+						"health": map[string]interface{}{"status": "Healthy"}},
+					map[string]interface{}{"group": "apps", "kind": "Deployment", "name": "web", "namespace": "test", "status": "Synced",
+						"health": map[string]interface{}{"status": "Degraded", "message": "exceeded its progress deadline"}},
+				},
+			},
+		}
+	}
+	render := func(obj map[string]interface{}) string {
+		te := newTestEngineWithResponses(t, "test", map[string]string{"/namespaces/test/deployments/web": deploymentJSON(4, 1, "")})
+		r := te.newObject(obj)
+		inCluster := obj["spec"].(map[string]interface{})["destination"].(map[string]interface{})["server"] == "https://kubernetes.default.svc"
+		got, err := r.renderTemplate("argocd_managed_resources", map[string]interface{}{"ctx": r, "inCluster": inCluster})
+		if err != nil {
+			t.Fatalf("renderTemplate() error = %v", err)
+		}
+		return got
+	}
+
+	got := render(application(map[string]interface{}{"server": "https://kubernetes.default.svc"}))
+	if !strings.Contains(got, "Manages 2 resources, 1 unhealthy") {
+		t.Errorf("argocd_managed_resources got = %q, should count the unhealthy entry", got)
+	}
+	if strings.Contains(got, "Service/web") {
+		t.Errorf("argocd_managed_resources got = %q, should leave the healthy Service out", got)
+	}
+	if !strings.Contains(got, "\n      Deployment/web") {
+		t.Errorf("argocd_managed_resources got = %q, should nest the Deployment's own summary", got)
+	}
+
+	got = render(application(map[string]interface{}{"name": "prod-eu-1"}))
+	if strings.Contains(got, "\n      Deployment/web") {
+		t.Errorf("argocd_managed_resources got = %q, should not look up a remote cluster's object here", got)
+	}
+}
+
+func TestArgoRolloutReplicaSetsTemplate(t *testing.T) {
+	replicaSet := func(hash, owner string, replicas int) string {
+		return fmt.Sprintf(`{"apiVersion":"apps/v1","kind":"ReplicaSet","metadata":{"name":"checkout-%[1]s","namespace":"shop","uid":"%[1]s",
+			"labels":{"app":"checkout","rollouts-pod-template-hash":"%[1]s"},
+			"ownerReferences":[{"apiVersion":"argoproj.io/v1alpha1","kind":"Rollout","name":"%[2]s","uid":"u"}]},
+			"spec":{"replicas":%[3]d},"status":{"replicas":%[3]d,"fullyLabeledReplicas":%[3]d,"readyReplicas":%[3]d,"availableReplicas":%[3]d}}`, hash, owner, replicas)
+	}
+	replicaSets := fmt.Sprintf(`{"apiVersion":"apps/v1","kind":"ReplicaSetList","metadata":{},"items":[%s,%s,%s,%s,%s]}`,
+		replicaSet("5b9c4d7f86", "checkout", 3),
+		replicaSet("6d8f7c9b54", "checkout", 2),
+		replicaSet("77f5c8d9b1", "checkout", 1),
+		replicaSet("4c8d7b6f52", "checkout", 0),
+		replicaSet("9a8b7c6d5e", "cart", 2),
+	)
+	te := newTestEngineWithResponses(t, "shop", map[string]string{"/namespaces/shop/replicasets": replicaSets})
+	r := te.newObject(map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"name": "checkout", "namespace": "shop"},
+		"spec":       map[string]interface{}{"strategy": map[string]interface{}{"canary": map[string]interface{}{}}},
+		"status":     map[string]interface{}{"stableRS": "5b9c4d7f86", "currentPodHash": "6d8f7c9b54"},
+	})
+	got, err := r.renderTemplate("argo_rollout_replicasets", r)
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	stable := strings.Index(got, "stable ReplicaSet/checkout-5b9c4d7f86")
+	canary := strings.Index(got, "canary ReplicaSet/checkout-6d8f7c9b54")
+	if stable < 0 || canary < 0 || stable > canary {
+		t.Errorf("argo_rollout_replicasets got = %q, should list the stable and then the canary ReplicaSet", got)
+	}
+	if !strings.Contains(got, "old ReplicaSet/checkout-77f5c8d9b1") {
+		t.Errorf("argo_rollout_replicasets got = %q, should list the older ReplicaSet still running Pods", got)
+	}
+	if strings.Contains(got, "checkout-4c8d7b6f52") || strings.Contains(got, "checkout-9a8b7c6d5e") {
+		t.Errorf("argo_rollout_replicasets got = %q, should skip scaled-down and foreign ReplicaSets", got)
+	}
+}
+
+func TestTektonStepsTemplate(t *testing.T) {
+	te := newTestEngineWithResponses(t, "ci", map[string]string{
+		"/namespaces/ci/pods/build-pod/log": "# example.com/api\n./main.go:12:2: undefined: handler\n",
+	})
+	r := te.newObject(map[string]interface{}{
+		"apiVersion": "tekton.dev/v1",
+		"kind":       "TaskRun",
+		"metadata":   map[string]interface{}{"name": "build", "namespace": "ci"},
+	})
+	step := func(name string, exitCode int64, reason string) map[string]interface{} {
+		return map[string]interface{}{
+			"name": name, "container": "step-" + name, "terminationReason": reason,
+			"terminated": map[string]interface{}{"exitCode": exitCode, "reason": "Error"},
+		}
+	}
+	status := map[string]interface{}{
+		"podName": "build-pod",
+		"steps": []interface{}{
+			step("fetch", 0, "Completed"),
+			step("lint", 1, "Continued"),
+			step("compile", 2, "Error"),
+			step("test", 1, "Skipped"),
+		},
+	}
+
+	got, err := r.renderTemplate("tekton_steps", map[string]interface{}{"ctx": r, "namespace": "ci", "status": status})
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	for _, want := range []string{"fetch Completed", "lint Continued after exit code 1", "compile Error, exit code 2", "test Skipped",
+		"Last failure logs:\n        # example.com/api\n        ./main.go:12:2: undefined: handler"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("tekton_steps got = %q, should contain %q", got, want)
+		}
+	}
+	var logRequests []string
+	for _, request := range te.requests {
+		if strings.Contains(request, "/log?") {
+			logRequests = append(logRequests, request)
+		}
+	}
+	if len(logRequests) != 1 || !strings.Contains(logRequests[0], "container=step-compile") {
+		t.Errorf("tekton_steps fetched logs %v, want only the failed step-compile container's", logRequests)
+	}
+
+	got, err = r.renderTemplate("tekton_steps", map[string]interface{}{"ctx": r, "namespace": "ci", "status": status, "onlyFailed": true, "indent": 6})
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	if !strings.HasPrefix(got, "\n      step compile Error") || strings.Contains(got, "fetch") || strings.Contains(got, "Steps:") {
+		t.Errorf("tekton_steps with onlyFailed got = %q, should render just the failed step", got)
+	}
+}
+
+func TestCNPGInstancesTemplate(t *testing.T) {
+	primaryStatus := `{"currentLsn":"1/0000A000","isPrimary":true,"replicationInfo":[
+		{"applicationName":"orders-db-2","state":"streaming","syncState":"quorum","replayLsn":"1/00008000","replayLag":1500000}]}`
+	responses := map[string]string{"/namespaces/orders/pods/https:orders-db-1:8000/proxy/pg/status": primaryStatus}
+	for _, name := range []string{"orders-db-1", "orders-db-2", "orders-db-3"} {
+		responses["/namespaces/orders/pods/"+name] = `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"` + name + `","namespace":"orders","uid":"` + name + `"},"status":{"phase":"Running"}}`
+	}
+	te := newTestEngineWithResponses(t, "orders", responses)
+	r := te.newObject(map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": "orders-db", "namespace": "orders"},
+		"status": map[string]interface{}{
+			"currentPrimary": "orders-db-1",
+			"instanceNames":  []interface{}{"orders-db-1", "orders-db-2", "orders-db-3"},
+		},
+	})
+	got, err := r.renderTemplate("cnpg_instances", r)
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	if !strings.Contains(got, "streaming, quorum, replay lag 2ms, 8.1kB behind") {
+		t.Errorf("cnpg_instances got = %q, should show orders-db-2's replication as the primary reports it", got)
+	}
+	if !strings.Contains(got, "orders-db-3") || !strings.Contains(got, "not streaming from the primary") {
+		t.Errorf("cnpg_instances got = %q, should call out orders-db-3 missing from the primary's replication", got)
+	}
+	if !slices.ContainsFunc(te.requests, func(request string) bool {
+		return strings.Contains(request, "/namespaces/orders/pods/https:orders-db-1:8000/proxy/pg/status")
+	}) {
+		t.Errorf("cnpg_instances requested %v, want the primary's instance manager status through the Pod proxy", te.requests)
+	}
+}
+
+func TestNetworkPolicyTemplateSelectedPods(t *testing.T) {
+	pod := func(name, namespace, labels, phase string) string {
+		return `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"` + name + `","namespace":"` + namespace + `","uid":"` + name +
+			`","labels":` + labels + `},"status":{"phase":"` + phase + `"}}`
+	}
+	lists := map[string]string{
+		"/namespaces": `{"apiVersion":"v1","kind":"NamespaceList","items":[
+			{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"shop","uid":"ns-shop","labels":{"team":"shop"}}},
+			{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"monitoring","uid":"ns-monitoring","labels":{"team":"ops"}}}]}`,
+		"/namespaces/shop/pods": `{"apiVersion":"v1","kind":"PodList","items":[` +
+			pod("api-1", "shop", `{"app":"api"}`, "Running") + `,` +
+			pod("api-2", "shop", `{"app":"api"}`, "Running") + `,` +
+			pod("api-migrate", "shop", `{"app":"api"}`, "Succeeded") + `,` +
+			pod("frontend-1", "shop", `{"role":"frontend"}`, "Running") + `]}`,
+		"/namespaces/monitoring/pods": `{"apiVersion":"v1","kind":"PodList","items":[` +
+			pod("prometheus-0", "monitoring", `{"app":"prometheus"}`, "Running") + `]}`,
+	}
+	te := newTestEngineWithResponses(t, "shop", lists)
+	selector := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"matchLabels": map[string]interface{}{key: value}}
+	}
+	r := te.newObject(map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "NetworkPolicy",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "shop"},
+		"spec": map[string]interface{}{
+			"podSelector": selector("app", "api"),
+			"ingress": []interface{}{
+				map[string]interface{}{"from": []interface{}{
+					map[string]interface{}{"podSelector": selector("role", "frontend")},
+					map[string]interface{}{"namespaceSelector": selector("team", "ops"), "podSelector": selector("app", "prometheus")},
+				}},
+				map[string]interface{}{"from": []interface{}{
+					map[string]interface{}{"podSelector": selector("role", "fronted")},
+				}},
+			},
+		},
+	})
+	got, err := r.renderTemplate("NetworkPolicy", r)
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	for _, want := range []string{
+		"Selects pods app=api: 2 pods api-1, api-2",
+		"from pods role=frontend: 1 pod frontend-1",
+		"from pods app=prometheus in namespaces team=ops: 1 pod monitoring/prometheus-0",
+		"rule 2 on all ports, admits nothing:",
+		"from pods role=fronted: selects no pods",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("NetworkPolicy got = %q, should contain %q", got, want)
+		}
+	}
+}
+
+func TestServiceAccountTemplate(t *testing.T) {
+	pod := func(name, owner, env string) string {
+		return `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"` + name + `","namespace":"shop","uid":"` + name +
+			`","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"` + owner + `","uid":"` + owner + `","controller":true}]},` +
+			`"spec":{"serviceAccountName":"api","containers":[{"name":"app","env":` + env + `}]},"status":{"phase":"Running"}}`
+	}
+	binding := func(kind, namespace, name, roleKind, role, subjects string) string {
+		meta := `"name":"` + name + `","uid":"` + name + `"`
+		if namespace != "" {
+			meta += `,"namespace":"` + namespace + `"`
+		}
+		return `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"` + kind + `","metadata":{` + meta + `},` +
+			`"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"` + roleKind + `","name":"` + role + `"},"subjects":` + subjects + `}`
+	}
+	responses := map[string]string{
+		"/namespaces/shop/pods": `{"apiVersion":"v1","kind":"PodList","items":[` +
+			pod("api-1", "api-7d9f", `[{"name":"AWS_ROLE_ARN","value":"arn:aws:iam::123456789012:role/shop-api"}]`) + `,` +
+			pod("api-2", "api-7d9f", `[]`) + `]}`,
+		"/namespaces/shop/secrets": `{"apiVersion":"v1","kind":"SecretList","items":[
+			{"apiVersion":"v1","kind":"Secret","type":"kubernetes.io/service-account-token","metadata":{"name":"api-token","namespace":"shop","uid":"api-token",
+				"annotations":{"kubernetes.io/service-account.name":"api"},"labels":{"kubernetes.io/legacy-token-last-used":"2024-04-30"}},"data":{"token":"dG9rZW4="}}]}`,
+		"/rolebindings": `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"RoleBindingList","items":[` +
+			binding("RoleBinding", "shop", "api-reader", "Role", "reader", `[{"kind":"ServiceAccount","name":"api","namespace":"shop"}]`) + `,` +
+			binding("RoleBinding", "shop", "web-reader", "Role", "reader", `[{"kind":"ServiceAccount","name":"web","namespace":"shop"}]`) + `]}`,
+		"/clusterrolebindings": `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRoleBindingList","items":[` +
+			binding("ClusterRoleBinding", "", "all-sa-admin", "ClusterRole", "cluster-admin", `[{"kind":"Group","name":"system:serviceaccounts"}]`) + `]}`,
+	}
+	te := newTestEngineWithResponses(t, "shop", responses)
+	r := te.newObject(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ServiceAccount",
+		"metadata": map[string]interface{}{
+			"name":        "api",
+			"namespace":   "shop",
+			"annotations": map[string]interface{}{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/shop-api"},
+		},
+		"imagePullSecrets": []interface{}{map[string]interface{}{"name": "ghcr-credentials"}},
+	})
+	got, err := r.renderTemplate("ServiceAccount", r)
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	for _, want := range []string{
+		"1 pod without AWS_ROLE_ARN: api-2",
+		"Secret/ghcr-credentials doesn't exist",
+		"Secret/api-token, last used 2024-04-30",
+		"Used by 2 pods: ReplicaSet/api-7d9f (2)",
+		"RoleBinding/api-reader: Role/reader missing, grants nothing",
+		"ClusterRoleBinding/all-sa-admin: ClusterRole/cluster-admin full cluster access (via Group/system:serviceaccounts)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ServiceAccount got = %q, should contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "web-reader") {
+		t.Errorf("ServiceAccount got = %q, shouldn't list bindings for other ServiceAccounts", got)
+	}
+}
+
+func TestRBACTemplates(t *testing.T) {
+	clusterRole := func(name, labels, aggregationRule string) string {
+		return `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRole","metadata":{"name":"` + name + `","uid":"` + name +
+			`","labels":` + labels + `}` + aggregationRule + `,"rules":[]}`
+	}
+	monitoringView := clusterRole("monitoring-view", `{}`, `,"aggregationRule":{"clusterRoleSelectors":[`+
+		`{"matchLabels":{"rbac.example.com/aggregate-to-monitoring-view":"true"}},{"matchLabels":{"rbac.example.com/aggregate-to-view":"true"}}]}`)
+	responses := map[string]string{
+		"/clusterroles": `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRoleList","items":[` + monitoringView + `,` +
+			clusterRole("prometheus-view", `{"rbac.example.com/aggregate-to-monitoring-view":"true"}`, "") + `]}`,
+		"/clusterroles/monitoring-view":        monitoringView,
+		"/namespaces/shop/serviceaccounts/api": `{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"api","namespace":"shop","uid":"api"}}`,
+		"/clusterrolebindings": `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRoleBindingList","items":[
+			{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRoleBinding","metadata":{"name":"grafana","uid":"grafana"},
+				"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"monitoring-view"},
+				"subjects":[{"kind":"ServiceAccount","name":"grafana","namespace":"monitoring"}]}]}`,
+	}
+	te := newTestEngineWithResponses(t, "shop", responses)
+	binding := func(kind, namespace, roleKind, roleName string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "api", "namespace": namespace, "uid": kind},
+			"roleRef":    map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": roleKind, "name": roleName},
+			"subjects": []interface{}{
+				map[string]interface{}{"kind": "ServiceAccount", "name": "api", "namespace": "shop"},
+				map[string]interface{}{"kind": "ServiceAccount", "name": "worker", "namespace": "shop"},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "missing role and ServiceAccount",
+			obj:  binding("RoleBinding", "shop", "Role", "api"),
+			want: []string{
+				"Role: Role/api not found, grants nothing",
+				"ServiceAccount/worker doesn't exist",
+			},
+			notWant: []string{"ServiceAccount/api doesn't exist"},
+		},
+		{
+			name: "aggregated role with an unmatched selector",
+			obj:  binding("ClusterRoleBinding", "", "ClusterRole", "monitoring-view"),
+			want: []string{
+				"Role: ClusterRole/monitoring-view, aggregation selectors match no ClusterRoles: rbac.example.com/aggregate-to-view=true",
+			},
+		},
+		{
+			name: "aggregated role",
+			obj: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRole",
+				"metadata":   map[string]interface{}{"name": "monitoring-view", "uid": "monitoring-view"},
+				"aggregationRule": map[string]interface{}{"clusterRoleSelectors": []interface{}{
+					map[string]interface{}{"matchLabels": map[string]interface{}{"rbac.example.com/aggregate-to-monitoring-view": "true"}},
+					map[string]interface{}{"matchLabels": map[string]interface{}{"rbac.example.com/aggregate-to-view": "true"}},
+				}},
+			},
+			want: []string{
+				"rbac.example.com/aggregate-to-monitoring-view=true: 1 ClusterRole prometheus-view",
+				"rbac.example.com/aggregate-to-view=true: matches no ClusterRoles",
+				"ClusterRoleBinding/grafana: ServiceAccount/grafana -n monitoring",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := te.newObject(tt.obj)
+			got, err := r.renderTemplate(r.Kind(), r)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("got = %q, should contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("got = %q, shouldn't contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
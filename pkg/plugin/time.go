@@ -73,6 +73,21 @@ func (cfg *RenderConfig) withinLastHour(kubeDate interface{}) bool {
 	return d >= 0 && d < time.Hour
 }
 
+// isPast reports whether kubeDate is already behind the render clock -- for the "expires at"
+// timestamps a controller deletes on its own schedule, where an expiry in the past means the
+// deletion is overdue rather than upcoming. False for a missing or unparseable date.
+func (cfg *RenderConfig) isPast(kubeDate interface{}) bool {
+	s, ok := kubeDate.(string)
+	if !ok || s == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return false
+	}
+	return t.Before(cfg.Now())
+}
+
 func (cfg *RenderConfig) relativeTime(kubeDate string) string {
 	if cfg.Viper.GetBool("absolute-time") {
 		return ""
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -31,3 +32,23 @@ func TestForOrSince(t *testing.T) {
 		t.Errorf("forOrSince() = %q, want %q", got, "since")
 	}
 }
+
+func TestIsPast(t *testing.T) {
+	cfg := NewRenderConfig(viper.New())
+	cfg.Now = func() time.Time { return time.Date(2026, 7, 14, 12, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		kubeDate interface{}
+		want     bool
+	}{
+		{"2026-07-14T11:59:59Z", true},
+		{"2026-07-14T12:00:01Z", false},
+		{"", false},
+		{"not a date", false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := cfg.isPast(tt.kubeDate); got != tt.want {
+			t.Errorf("isPast(%v) = %v, want %v", tt.kubeDate, got, tt.want)
+		}
+	}
+}
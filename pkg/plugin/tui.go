@@ -0,0 +1,475 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// tuiRefreshInterval is how often --tui re-queries the cluster and re-renders what it shows.
+const tuiRefreshInterval = 5 * time.Second
+
+// tuiItem is one line of a list: the object and its one-line HealthSummary.
+type tuiItem struct {
+	obj     RenderableObject
+	summary string
+}
+
+// tuiPage is one object's full render, pushed on top of the list (or of the page it was reached
+// from) when it is opened. links are the objects the render looked up along the way -- its
+// owners and whatever the template fetched by reference, e.g. a deep_render_ref target -- which
+// the related-objects picker offers to follow.
+type tuiPage struct {
+	obj    RenderableObject
+	lines  []string
+	links  []tuiItem
+	offset int
+	// picking is set while the related-objects picker is open, linkCursor is its selection.
+	picking    bool
+	linkCursor int
+}
+
+// tui is the state of `--tui`, kept apart from the terminal so it can be driven by tests: the
+// terminal loop only feeds it keys and ticks and prints what view returns.
+type tui struct {
+	// list re-runs the query, fetch re-reads a single object, render renders one in full and
+	// returns the objects it looked up along the way, and summarize renders its one-line summary.
+	list      func() ([]RenderableObject, error)
+	fetch     func(RenderableObject) (RenderableObject, error)
+	render    func(RenderableObject) (string, []RenderableObject)
+	summarize func(RenderableObject) string
+
+	width, height int
+	items         []tuiItem
+	cursor        int
+	listOffset    int
+	pages         []*tuiPage
+	status        string
+}
+
+func (t *tui) newItem(r RenderableObject) tuiItem {
+	return tuiItem{obj: r, summary: t.summarize(r)}
+}
+
+// newPage renders r, keeping the scroll position and link selection of previous when it is the
+// same page being refreshed.
+func (t *tui) newPage(r RenderableObject, previous *tuiPage) *tuiPage {
+	text, related := t.render(r)
+	page := &tuiPage{obj: r, lines: strings.Split(strings.Trim(expandTabs(text), "\n"), "\n")}
+	for _, nr := range related {
+		page.links = append(page.links, t.newItem(nr))
+	}
+	if previous != nil {
+		page.offset, page.picking, page.linkCursor = previous.offset, previous.picking, previous.linkCursor
+		page.offset = clamp(page.offset, 0, page.maxOffset(t.bodyHeight()))
+		page.linkCursor = clamp(page.linkCursor, 0, len(page.links)-1)
+		page.picking = page.picking && len(page.links) > 0
+	}
+	return page
+}
+
+// reload re-runs the query and re-renders the page on top, keeping the selection on the same
+// objects where they still exist. Pages below the top one are re-rendered once they are
+// returned to.
+func (t *tui) reload() {
+	objects, err := t.list()
+	t.status = fmt.Sprintf("refreshed at %s", time.Now().Format(time.TimeOnly))
+	if err != nil {
+		t.status = fmt.Sprintf("refresh failed: %s", err)
+	}
+	selected := ""
+	if t.cursor < len(t.items) {
+		selected = objectKey(t.items[t.cursor].obj)
+	}
+	t.items = t.items[:0]
+	for _, r := range objects {
+		if objectKey(r) == selected {
+			t.cursor = len(t.items)
+		}
+		t.items = append(t.items, t.newItem(r))
+	}
+	t.cursor = clamp(t.cursor, 0, len(t.items)-1)
+	if len(t.pages) > 0 {
+		t.refreshTop()
+	}
+}
+
+func (t *tui) refreshTop() {
+	top := t.pages[len(t.pages)-1]
+	r, err := t.fetch(top.obj)
+	if err != nil {
+		t.status = fmt.Sprintf("refresh of %s failed: %s", top.obj.String(), err)
+		r = top.obj
+	}
+	t.pages[len(t.pages)-1] = t.newPage(r, top)
+}
+
+func (t *tui) open(r RenderableObject) {
+	t.pages = append(t.pages, t.newPage(r, nil))
+}
+
+func (t *tui) back() {
+	if len(t.pages) == 0 {
+		return
+	}
+	t.pages = t.pages[:len(t.pages)-1]
+	if len(t.pages) > 0 {
+		t.refreshTop()
+	}
+}
+
+// bodyHeight is the number of lines between the header and the status line.
+func (t *tui) bodyHeight() int {
+	return max(t.height-2, 1)
+}
+
+func (p *tuiPage) maxOffset(height int) int {
+	return max(len(p.lines)-height, 0)
+}
+
+// handleKey applies one key press, as named by parseKeys, and reports whether to quit.
+func (t *tui) handleKey(key string) (quit bool) {
+	switch key {
+	case "q", "ctrl+c":
+		return true
+	case "r":
+		t.reload()
+		return false
+	}
+	if len(t.pages) == 0 {
+		t.handleListKey(key)
+		return false
+	}
+	page := t.pages[len(t.pages)-1]
+	if page.picking {
+		t.handlePickerKey(page, key)
+		return false
+	}
+	height := t.bodyHeight()
+	switch key {
+	case "up", "k":
+		page.offset--
+	case "down", "j":
+		page.offset++
+	case "pgup":
+		page.offset -= height
+	case "pgdown", " ":
+		page.offset += height
+	case "home", "g":
+		page.offset = 0
+	case "end", "G":
+		page.offset = page.maxOffset(height)
+	case "tab", "right", "l":
+		page.picking = len(page.links) > 0
+	case "esc", "left", "h", "backspace":
+		t.back()
+		return false
+	}
+	page.offset = clamp(page.offset, 0, page.maxOffset(height))
+	return false
+}
+
+func (t *tui) handleListKey(key string) {
+	switch key {
+	case "up", "k":
+		t.cursor--
+	case "down", "j":
+		t.cursor++
+	case "pgup":
+		t.cursor -= t.bodyHeight()
+	case "pgdown", " ":
+		t.cursor += t.bodyHeight()
+	case "home", "g":
+		t.cursor = 0
+	case "end", "G":
+		t.cursor = len(t.items) - 1
+	case "enter", "right", "l":
+		if t.cursor < len(t.items) {
+			t.open(t.items[t.cursor].obj)
+		}
+	}
+	t.cursor = clamp(t.cursor, 0, len(t.items)-1)
+}
+
+func (t *tui) handlePickerKey(page *tuiPage, key string) {
+	switch key {
+	case "up", "k":
+		page.linkCursor--
+	case "down", "j":
+		page.linkCursor++
+	case "home", "g":
+		page.linkCursor = 0
+	case "end", "G":
+		page.linkCursor = len(page.links) - 1
+	case "enter", "right", "l":
+		page.picking = false
+		t.open(page.links[page.linkCursor].obj)
+	case "esc", "tab", "left", "h", "backspace":
+		page.picking = false
+	}
+	page.linkCursor = clamp(page.linkCursor, 0, len(page.links)-1)
+}
+
+// view returns the screen's lines, each already cut to the terminal's width.
+func (t *tui) view() []string {
+	var header, footer string
+	var body []string
+	height := t.bodyHeight()
+	switch {
+	case len(t.pages) == 0:
+		header = fmt.Sprintf("%d resources · ↑/↓ move · enter open · r refresh · q quit", len(t.items))
+		t.listOffset = scrollTo(t.listOffset, t.cursor, height)
+		body = selectionLines(t.items, t.cursor, t.listOffset, height)
+		if len(t.items) == 0 {
+			body = []string{"no resources found"}
+		}
+	case t.pages[len(t.pages)-1].picking:
+		page := t.pages[len(t.pages)-1]
+		header = fmt.Sprintf("%s › related objects · ↑/↓ move · enter open · esc close", t.breadcrumb())
+		offset := scrollTo(0, page.linkCursor, height)
+		body = selectionLines(page.links, page.linkCursor, offset, height)
+	default:
+		page := t.pages[len(t.pages)-1]
+		header = fmt.Sprintf("%s · ↑/↓ scroll · tab %d related · esc back · q quit", t.breadcrumb(), len(page.links))
+		end := min(page.offset+height, len(page.lines))
+		body = page.lines[page.offset:end]
+		footer = fmt.Sprintf("lines %d-%d of %d · ", page.offset+1, end, len(page.lines))
+	}
+	lines := []string{"\x1b[1m" + header + "\x1b[0m"}
+	lines = append(lines, body...)
+	for len(lines) < height+1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, "\x1b[2m"+footer+t.status+"\x1b[0m")
+	for i, line := range lines {
+		lines[i] = truncateANSI(line, t.width)
+	}
+	return lines
+}
+
+func (t *tui) breadcrumb() string {
+	var names []string
+	for _, page := range t.pages {
+		names = append(names, page.obj.String())
+	}
+	return strings.Join(names, " › ")
+}
+
+// scrollTo returns the offset closest to offset that keeps line cursor within a window of height.
+func scrollTo(offset, cursor, height int) int {
+	if cursor < offset {
+		return cursor
+	}
+	if cursor >= offset+height {
+		return cursor - height + 1
+	}
+	return offset
+}
+
+func selectionLines(items []tuiItem, cursor, offset, height int) (out []string) {
+	for i := offset; i < len(items) && i < offset+height; i++ {
+		marker := "  "
+		if i == cursor {
+			marker = "\x1b[1m▶\x1b[0m "
+		}
+		out = append(out, marker+items[i].summary)
+	}
+	return out
+}
+
+func clamp(v, low, high int) int {
+	return max(low, min(v, high))
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
+
+// truncateANSI cuts s to width visible runes, leaving its ANSI escape sequences out of the count
+// and resetting the attributes at the cut so the colour doesn't run on into the next line.
+func truncateANSI(s string, width int) string {
+	visible := 0
+	inEscape := false
+	for i, c := range s {
+		switch {
+		case inEscape:
+			inEscape = !(c >= '@' && c <= '~' && c != '[')
+		case c == '\x1b':
+			inEscape = true
+		default:
+			if visible == width {
+				return s[:i] + "\x1b[0m"
+			}
+			visible++
+		}
+	}
+	return s
+}
+
+// parseKeys names the keys in one read from a terminal in raw mode. Escape sequences are only
+// recognised whole, which is how terminals send them.
+func parseKeys(b []byte) (keys []string) {
+	sequences := map[string]string{
+		"\x1b[A": "up", "\x1b[B": "down", "\x1b[C": "right", "\x1b[D": "left",
+		"\x1bOA": "up", "\x1bOB": "down", "\x1bOC": "right", "\x1bOD": "left",
+		"\x1b[5~": "pgup", "\x1b[6~": "pgdown",
+		"\x1b[H": "home", "\x1b[F": "end", "\x1b[1~": "home", "\x1b[4~": "end",
+	}
+	s := string(b)
+	for len(s) > 0 {
+		if s[0] == '\x1b' {
+			matched := false
+			for seq, key := range sequences {
+				if strings.HasPrefix(s, seq) {
+					keys = append(keys, key)
+					s = s[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				keys = append(keys, "esc")
+				s = s[1:]
+			}
+			continue
+		}
+		switch s[0] {
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case 0x7f, 0x08:
+			keys = append(keys, "backspace")
+		case 0x03:
+			keys = append(keys, "ctrl+c")
+		default:
+			keys = append(keys, s[:1])
+		}
+		s = s[1:]
+	}
+	return keys
+}
+
+// newClusterTUI is the tui over the live query: refreshes forget the repo's caches, so every
+// render sees the cluster as it is now.
+func newClusterTUI(args []string, engine *renderEngine, repo *input.ResourceRepo) *tui {
+	return &tui{
+		list: func() ([]RenderableObject, error) {
+			repo.ResetCaches()
+			objects, err := listObjects(repo.CLIQueryResults(args), engine, repo)
+			// The list only shows summaries, which need owners and events but none of the
+			// kind-specific lists a full render goes through.
+			if len(objects) > 0 && !objects[0].LiveQueriesDisabled() {
+				repo.Prefetch(newPrefetchPlan(objects, false, repo).loads)
+			}
+			return objects, err
+		},
+		fetch: func(r RenderableObject) (RenderableObject, error) {
+			if r.Config.GetBool("local") {
+				return r, nil
+			}
+			gvk := r.GroupVersionKind()
+			kind := gvk.Kind
+			if gvk.Group != "" {
+				kind = strings.Join([]string{gvk.Kind, gvk.Version, gvk.Group}, ".")
+			}
+			obj, err := repo.FirstObject(r.Namespace(), []string{kind, r.Name()}, "")
+			if err != nil {
+				return r, err
+			}
+			return r.newRenderableObject(obj), nil
+		},
+		render: func(r RenderableObject) (string, []RenderableObject) {
+			var related []RenderableObject
+			seen := map[string]bool{objectKey(r): true}
+			engine.related = func(nr RenderableObject) {
+				if nr.Object == nil || seen[objectKey(nr)] {
+					return
+				}
+				seen[objectKey(nr)] = true
+				related = append(related, nr)
+			}
+			defer func() { engine.related = nil }()
+			engine.renderedUIDs = make(uidSet)
+			text, err := r.renderString()
+			if err != nil {
+				text += fmt.Sprintf("\n\nFailed to render: %s", err)
+			}
+			return text, related
+		},
+		summarize: func(r RenderableObject) string {
+			summary, err := r.HealthSummary("")
+			if err != nil {
+				return fmt.Sprintf("%s: failed to render: %s", r.String(), err)
+			}
+			return summary
+		},
+	}
+}
+
+// runTUI is `--tui`: an interactive, full-screen browser over the query's objects. It starts
+// with their one-line summaries, opens an object's full render on enter, follows the owners
+// and the objects a render looked up from there, and re-runs everything every
+// tuiRefreshInterval.
+func runTUI(args []string, engine *renderEngine, repo *input.ResourceRepo) error {
+	out, ok := engine.ioStreams.Out.(*os.File)
+	if !ok || !term.IsTerminal(int(out.Fd())) || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("--tui needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = term.Restore(int(os.Stdin.Fd()), state) }()
+	// The alternate screen keeps the user's scrollback as it was once the browser quits.
+	_, _ = io.WriteString(out, "\x1b[?1049h\x1b[?25l")
+	defer func() { _, _ = io.WriteString(out, "\x1b[?25h\x1b[?1049l") }()
+
+	keys := make(chan []string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				klog.V(1).ErrorS(err, "Reading the terminal failed")
+				keys <- []string{"q"}
+				return
+			}
+			keys <- parseKeys(buf[:n])
+		}
+	}()
+	t := newClusterTUI(args, engine, repo)
+	t.status = "loading..."
+	draw := func() {
+		t.width, t.height, err = term.GetSize(int(out.Fd()))
+		if err != nil {
+			t.width, t.height = 80, 24
+		}
+		_, _ = io.WriteString(out, "\x1b[H\x1b[2J"+strings.Join(t.view(), "\r\n"))
+	}
+	draw()
+	t.reload()
+	ticker := time.NewTicker(tuiRefreshInterval)
+	defer ticker.Stop()
+	for {
+		draw()
+		select {
+		case pressed := <-keys:
+			for _, key := range pressed {
+				if t.handleKey(key) {
+					return nil
+				}
+			}
+		case <-ticker.C:
+			t.reload()
+		}
+	}
+}
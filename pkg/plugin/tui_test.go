@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeTUI is a tui over a fixed set of Pods whose render of web-0 "looks up" its ReplicaSet.
+func newFakeTUI() *tui {
+	pods := []RenderableObject{
+		testWatchedObject("Pod", "web", "web-0", "0"),
+		testWatchedObject("Pod", "web", "web-1", "1"),
+		testWatchedObject("Pod", "web", "web-2", "2"),
+	}
+	replicaSet := testWatchedObject("ReplicaSet", "web", "web-5d8f", "rs")
+	return &tui{
+		width:  40,
+		height: 6,
+		list:   func() ([]RenderableObject, error) { return pods, nil },
+		fetch:  func(r RenderableObject) (RenderableObject, error) { return r, nil },
+		render: func(r RenderableObject) (string, []RenderableObject) {
+			lines := []string{r.String()}
+			for i := 0; i < 10; i++ {
+				lines = append(lines, "\tline")
+			}
+			if r.Name() == "web-0" {
+				return strings.Join(lines, "\n"), []RenderableObject{replicaSet}
+			}
+			return strings.Join(lines, "\n"), nil
+		},
+		summarize: func(r RenderableObject) string { return r.Kind() + " " + r.Name() },
+	}
+}
+
+func TestTUINavigation(t *testing.T) {
+	ui := newFakeTUI()
+	ui.reload()
+	require.Len(t, ui.items, 3)
+
+	view := ui.view()
+	assert.Len(t, view, 6)
+	assert.Contains(t, view[1], "▶")
+	assert.Contains(t, view[1], "Pod web-0")
+
+	ui.handleKey("down")
+	ui.handleKey("down")
+	ui.handleKey("down")
+	assert.Equal(t, 2, ui.cursor, "the cursor stops at the last item")
+	ui.handleKey("home")
+
+	ui.handleKey("enter")
+	require.Len(t, ui.pages, 1)
+	page := ui.pages[0]
+	assert.Equal(t, "web-0", page.obj.Name())
+	assert.Equal(t, "    line", page.lines[1], "tabs are expanded")
+	require.Len(t, page.links, 1)
+	assert.Equal(t, "ReplicaSet web-5d8f", page.links[0].summary)
+
+	ui.handleKey("end")
+	assert.Equal(t, 11-4, page.offset, "scrolling stops with the last line at the bottom")
+	ui.reload()
+	assert.Equal(t, 7, ui.pages[0].offset, "a refresh keeps the scroll position")
+
+	ui.handleKey("tab")
+	assert.True(t, ui.pages[0].picking)
+	assert.Contains(t, strings.Join(ui.view(), "\n"), "ReplicaSet web-5d8f")
+	ui.handleKey("enter")
+	require.Len(t, ui.pages, 2)
+	assert.Equal(t, "web-5d8f", ui.pages[1].obj.Name())
+	assert.Contains(t, ui.view()[0], "Pod/web-0")
+
+	ui.handleKey("esc")
+	ui.handleKey("esc")
+	assert.Empty(t, ui.pages)
+	assert.True(t, ui.handleKey("q"))
+}
+
+func TestTruncateANSI(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{name: "short enough", in: "abc", width: 5, want: "abc"},
+		{name: "plain", in: "abcdef", width: 3, want: "abc\x1b[0m"},
+		{name: "escapes don't count", in: "\x1b[31mred\x1b[0m and more", width: 5, want: "\x1b[31mred\x1b[0m a\x1b[0m"},
+		{name: "runes count once", in: "▶ abc", width: 3, want: "▶ a\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, truncateANSI(tt.in, tt.width))
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t, []string{"up", "down", "enter", "q"}, parseKeys([]byte("\x1b[A\x1b[B\rq")))
+	assert.Equal(t, []string{"pgdown", "esc", "tab", "backspace"}, parseKeys([]byte("\x1b[6~\x1b\t\x7f")))
+}
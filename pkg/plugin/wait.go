@@ -0,0 +1,235 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/resource"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// waitTracker keeps the latest version of every object --wait-for=healthy is waiting on, in the
+// order they were first seen, so the final render lists them the way the initial query did.
+// Deleted objects stay tracked (as deleted) rather than being dropped: a Deployment deleted
+// while a pipeline waits for it to roll out is a failure to report, not one fewer thing to wait
+// for.
+type waitTracker struct {
+	engine  *renderEngine
+	repo    *input.ResourceRepo
+	keys    []string
+	objects map[string]RenderableObject
+	deleted map[string]bool
+}
+
+func newWaitTracker(engine *renderEngine, repo *input.ResourceRepo) *waitTracker {
+	return &waitTracker{
+		engine:  engine,
+		repo:    repo,
+		objects: map[string]RenderableObject{},
+		deleted: map[string]bool{},
+	}
+}
+
+func (t *waitTracker) update(obj runtime.Object, deleted bool) error {
+	out, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	r := newRenderableObject(out, t.engine, t.repo)
+	key := r.String()
+	if _, ok := t.objects[key]; !ok {
+		t.keys = append(t.keys, key)
+	}
+	t.objects[key] = r
+	t.deleted[key] = deleted
+	return nil
+}
+
+// settled reports whether waiting any longer is pointless: either every tracked object is
+// Current, or one of them has reached a state that more waiting won't fix (kstatus Failed, or
+// deleted).
+func (t *waitTracker) settled() bool {
+	allCurrent := true
+	for _, key := range t.keys {
+		if t.deleted[key] {
+			return true
+		}
+		result := t.objects[key].KStatus()
+		if result != nil && result.Status == kstatus.FailedStatus {
+			return true
+		}
+		if result == nil || result.Status != kstatus.CurrentStatus {
+			allCurrent = false
+		}
+	}
+	return allCurrent
+}
+
+func (t *waitTracker) tally() *healthTally {
+	tally := newHealthTally()
+	for _, key := range t.keys {
+		if t.deleted[key] {
+			tally.total++
+			tally.notFound++
+			continue
+		}
+		tally.add(t.objects[key])
+	}
+	return tally
+}
+
+// condition is the watchtools.ConditionFunc the wait loop runs on every watch event.
+func (t *waitTracker) condition(e watch.Event) (bool, error) {
+	klog.V(5).InfoS("Processing wait event", "e", e)
+	switch e.Type {
+	case watch.Error:
+		return false, apierrors.FromObject(e.Object)
+	case watch.Bookmark:
+		return false, nil
+	}
+	if err := t.update(e.Object, e.Type == watch.Deleted); err != nil {
+		return false, err
+	}
+	return t.settled(), nil
+}
+
+// runWaitForHealthy is --wait-for=healthy: it lists the requested objects, then follows the same
+// watch runWatch uses until every one of them is Current -- or until one fails, is deleted, or
+// --timeout passes -- and only then renders each of them once, in full. Nothing is rendered while
+// waiting, so unlike --watch there is no reason to drop to shallow mode, and the repo's caches
+// are only populated by that single final render.
+func runWaitForHealthy(results *resource.Result, engine *renderEngine, repo *input.ResourceRepo, cfg *RenderConfig) error {
+	streams := engine.ioStreams
+	tracker := newWaitTracker(engine, repo)
+	err := results.Visit(func(resourceInfo *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		return tracker.update(resourceInfo.Object, false)
+	})
+	if err != nil {
+		klog.V(1).ErrorS(err, "Error querying resources")
+		return err
+	}
+	if len(tracker.keys) == 0 {
+		return fmt.Errorf("no resources found")
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout := cfg.Viper.GetDuration("timeout"); timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+	var waitErr error
+	if !tracker.settled() {
+		_, _ = color.New(color.FgHiYellow).Fprintf(streams.ErrOut, "Waiting for %d resources to become healthy...\n", len(tracker.keys))
+		w, list, err := startWatch(results)
+		if err != nil {
+			return err
+		}
+		rv, err := meta.NewAccessor().ResourceVersion(list)
+		if err != nil {
+			return err
+		}
+		intr := interrupt.New(nil, cancel)
+		waitErr = intr.Run(func() error {
+			return untilSettled(ctx, w, rv, results.Watch, tracker.condition)
+		})
+	}
+	var remaining []RenderableObject
+	for _, key := range tracker.keys {
+		if !tracker.deleted[key] {
+			remaining = append(remaining, tracker.objects[key])
+		}
+	}
+	prefetchRelated(remaining, engine, repo)
+	for _, key := range tracker.keys {
+		if tracker.deleted[key] {
+			if engine.reportOutput() != "" {
+				engine.reports = append(engine.reports, HealthReport{
+					ObjectRef: objectRef(tracker.objects[key]),
+					Status:    string(kstatus.NotFoundStatus),
+					Message:   "deleted while waiting for it to become healthy",
+				})
+				continue
+			}
+			_, _ = fmt.Fprintln(streams.Out)
+			errorPrintf(streams.Out, "%s was deleted while waiting for it to become healthy", key)
+			continue
+		}
+		renderObj(tracker.objects[key], engine)
+	}
+	return waitVerdict(tracker.tally(), ctx, waitErr, cfg)
+}
+
+// watchReconnectDelay is how long untilSettled waits before re-opening a watch the API server
+// closed, so a server that keeps closing it straight away isn't hammered with new ones.
+var watchReconnectDelay = time.Second
+
+// untilSettled runs condition over w's events until it reports done or ctx ends. The API server
+// closes every watch after a while (minutes, by default), which says nothing about the objects,
+// so a closed watch is re-opened with rewatch from the last resourceVersion seen rather than
+// ending the wait -- with --timeout=0 there would otherwise be no waiting "forever".
+func untilSettled(ctx context.Context, w watch.Interface, rv string, rewatch func(resourceVersion string) (watch.Interface, error), condition watchtools.ConditionFunc) error {
+	for {
+		_, err := watchtools.UntilWithoutRetry(ctx, w, func(e watch.Event) (bool, error) {
+			if e.Type != watch.Error {
+				if accessor, err := meta.Accessor(e.Object); err == nil && accessor.GetResourceVersion() != "" {
+					rv = accessor.GetResourceVersion()
+				}
+			}
+			return condition(e)
+		})
+		if !errors.Is(err, watchtools.ErrWatchClosed) {
+			return err
+		}
+		klog.V(2).InfoS("Wait watch closed, re-opening it", "rv", rv)
+		select {
+		case <-ctx.Done():
+			return wait.ErrorInterrupted(ctx.Err())
+		case <-time.After(watchReconnectDelay):
+		}
+		if w, err = rewatch(rv); err != nil {
+			return err
+		}
+	}
+}
+
+// waitVerdict turns the outcome of the wait into Run's return value: nil once everything is
+// Current, a HealthExitError under --exit-code, and a plain error explaining why the wait ended
+// otherwise.
+func waitVerdict(tally *healthTally, ctx context.Context, waitErr error, cfg *RenderConfig) error {
+	healthErr := tally.exitError()
+	if healthErr == nil {
+		return nil
+	}
+	if waitErr != nil && !wait.Interrupted(waitErr) {
+		return waitErr
+	}
+	if cfg.Viper.GetBool("exit-code") {
+		return healthErr
+	}
+	reason := "gave up waiting"
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		reason = fmt.Sprintf("timed out after %s waiting", cfg.Viper.GetDuration("timeout"))
+	case errors.Is(ctx.Err(), context.Canceled):
+		reason = "interrupted while waiting"
+	}
+	return fmt.Errorf("%s for resources to become healthy, %s", reason, tally.summary())
+}
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+func testPodObject(name, phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "ns"},
+		"status":     map[string]interface{}{"phase": phase},
+	}}
+}
+
+func newTestWaitTracker(t *testing.T, v *viper.Viper) *waitTracker {
+	t.Helper()
+	e, err := newRenderEngine(genericiooptions.NewTestIOStreamsDiscard(), NewRenderConfig(v))
+	require.NoError(t, err)
+	return newWaitTracker(e, nil)
+}
+
+// TestWaitTrackerSettles drives the --wait-for=healthy condition through a fake watch: it keeps
+// waiting while any tracked object is still in progress, and stops as soon as all are Current, or
+// as soon as one is deleted, without waiting for the rest.
+func TestWaitTrackerSettles(t *testing.T) {
+	tests := []struct {
+		name        string
+		events      []watch.Event
+		wantSummary string
+	}{
+		{
+			name: "all become current",
+			events: []watch.Event{
+				{Type: watch.Modified, Object: testPodObject("a", "Succeeded")},
+				{Type: watch.Modified, Object: testPodObject("b", "Succeeded")},
+			},
+		},
+		{
+			name: "deleted while waiting",
+			events: []watch.Event{
+				{Type: watch.Deleted, Object: testPodObject("a", "Pending")},
+			},
+			wantSummary: "2 of 2 resources are not Current: 1 NotFound, 1 InProgress",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTestWaitTracker(t, viper.New())
+			require.NoError(t, tracker.update(testPodObject("a", "Pending"), false))
+			require.NoError(t, tracker.update(testPodObject("b", "Pending"), false))
+			require.False(t, tracker.settled())
+
+			w := watch.NewFakeWithChanSize(len(tt.events), false)
+			for _, e := range tt.events {
+				w.Action(e.Type, e.Object)
+			}
+			_, err := watchtools.UntilWithoutRetry(context.Background(), w, tracker.condition)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Pod/a[ns]", "Pod/b[ns]"}, tracker.keys)
+
+			verdict := waitVerdict(tracker.tally(), context.Background(), nil, NewRenderConfig(viper.New()))
+			if tt.wantSummary == "" {
+				assert.NoError(t, verdict)
+			} else {
+				assert.ErrorContains(t, verdict, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestWaitVerdictTimeout(t *testing.T) {
+	v := viper.New()
+	v.Set("timeout", "1ms")
+	tracker := newTestWaitTracker(t, v)
+	require.NoError(t, tracker.update(testPodObject("a", "Pending"), false))
+	ctx, cancel := context.WithTimeout(context.Background(), v.GetDuration("timeout"))
+	defer cancel()
+	_, waitErr := watchtools.UntilWithoutRetry(ctx, watch.NewFake(), tracker.condition)
+	require.Error(t, waitErr)
+
+	err := waitVerdict(tracker.tally(), ctx, waitErr, NewRenderConfig(v))
+	assert.EqualError(t, err, "timed out after 1ms waiting for resources to become healthy, 1 of 1 resources are not Current: 1 InProgress")
+
+	v.Set("exit-code", true)
+	err = waitVerdict(tracker.tally(), ctx, waitErr, NewRenderConfig(v))
+	var healthErr *HealthExitError
+	require.ErrorAs(t, err, &healthErr)
+	assert.Equal(t, ExitCodeInProgress, healthErr.Code)
+}
+
+// TestUntilSettledReopensClosedWatch covers the API server closing the watch before anything is
+// healthy: the wait carries on over a new watch from the last resourceVersion it saw instead of
+// ending with a not-healthy verdict.
+func TestUntilSettledReopensClosedWatch(t *testing.T) {
+	watchReconnectDelay = 0
+	t.Cleanup(func() { watchReconnectDelay = time.Second })
+	tracker := newTestWaitTracker(t, viper.New())
+	require.NoError(t, tracker.update(testPodObject("a", "Pending"), false))
+	require.NoError(t, tracker.update(testPodObject("b", "Pending"), false))
+
+	withRV := func(obj *unstructured.Unstructured, rv string) *unstructured.Unstructured {
+		obj.SetResourceVersion(rv)
+		return obj
+	}
+	first := watch.NewFakeWithChanSize(1, false)
+	first.Modify(withRV(testPodObject("a", "Succeeded"), "11"))
+	first.Stop()
+	second := watch.NewFakeWithChanSize(1, false)
+	second.Modify(withRV(testPodObject("b", "Succeeded"), "12"))
+
+	var rewatchedFrom []string
+	rewatch := func(rv string) (watch.Interface, error) {
+		rewatchedFrom = append(rewatchedFrom, rv)
+		return second, nil
+	}
+	err := untilSettled(context.Background(), first, "10", rewatch, tracker.condition)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"11"}, rewatchedFrom)
+	assert.NoError(t, waitVerdict(tracker.tally(), context.Background(), err, NewRenderConfig(viper.New())))
+}
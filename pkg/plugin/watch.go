@@ -0,0 +1,217 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/fatih/color"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/resource"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/interrupt"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+// deepWatchRerenderDelay is how long related changes are collected before the objects they bear
+// on are re-rendered: a rollout touches dozens of Pods and Events within a second, and one
+// re-render of the Deployment per burst is all anyone can read anyway.
+const deepWatchRerenderDelay = time.Second
+
+// relatedOwnerDepth bounds how far up ownerReferences a related change is traced, enough for
+// Pod -> ReplicaSet -> Deployment and Pod -> Job -> CronJob with room to spare.
+const relatedOwnerDepth = 4
+
+// relatedChange is one add/update/delete an informer reported.
+type relatedChange struct {
+	gvr schema.GroupVersionResource
+	obj input.Object
+}
+
+// deepWatch is the state of `--watch --deep`: the latest version of every object the query's own
+// watch has reported, in the order first seen, plus those waiting to be re-rendered because
+// something related to them changed.
+type deepWatch struct {
+	engine  *renderEngine
+	repo    *input.ResourceRepo
+	uids    []types.UID
+	watched map[types.UID]RenderableObject
+	dirty   map[types.UID]bool
+}
+
+func newDeepWatch(objects []RenderableObject, engine *renderEngine, repo *input.ResourceRepo) *deepWatch {
+	d := &deepWatch{engine: engine, repo: repo, watched: map[types.UID]RenderableObject{}, dirty: map[types.UID]bool{}}
+	for _, r := range objects {
+		d.track(r, false)
+	}
+	return d
+}
+
+func (d *deepWatch) track(r RenderableObject, deleted bool) {
+	uid := r.GetUID()
+	if deleted {
+		delete(d.watched, uid)
+		delete(d.dirty, uid)
+		return
+	}
+	if _, ok := d.watched[uid]; !ok {
+		d.uids = append(d.uids, uid)
+	}
+	d.watched[uid] = r
+}
+
+// render renders r against the cluster as it is now: whatever the informers don't serve is
+// looked up afresh rather than from the caches of the previous render.
+func (d *deepWatch) render(r RenderableObject) {
+	d.repo.ResetCaches()
+	renderObj(r, d.engine)
+}
+
+// related returns the watched objects obj bears on: the object an Event is about, the Service an
+// EndpointSlice belongs to, the Node a Pod runs on, and any watched owner up a Pod's or
+// ReplicaSet's ownerReferences chain. A change to a watched object itself is left to the query's
+// own watch, which reports it anyway.
+func (d *deepWatch) related(change relatedChange) (out []types.UID) {
+	u := unstructured.Unstructured{Object: change.obj}
+	if _, ok := d.watched[u.GetUID()]; ok {
+		return nil
+	}
+	add := func(uid types.UID) {
+		if _, ok := d.watched[uid]; ok {
+			out = append(out, uid)
+		}
+	}
+	switch change.gvr.Resource {
+	case "events":
+		uid, _, _ := unstructured.NestedString(change.obj, "involvedObject", "uid")
+		add(types.UID(uid))
+		return out
+	case "endpointslices":
+		if service := u.GetLabels()[discoveryv1.LabelServiceName]; service != "" {
+			out = append(out, d.find("Service", u.GetNamespace(), service)...)
+		}
+	case "pods":
+		if node, _, _ := unstructured.NestedString(change.obj, "spec", "nodeName"); node != "" {
+			out = append(out, d.find("Node", "", node)...)
+		}
+	}
+	d.walkOwners(u.GetNamespace(), u.GetOwnerReferences(), relatedOwnerDepth, add)
+	return out
+}
+
+func (d *deepWatch) find(kind, namespace, name string) (out []types.UID) {
+	for uid, r := range d.watched {
+		if r.Kind() == kind && r.Namespace() == namespace && r.Name() == name {
+			out = append(out, uid)
+		}
+	}
+	return out
+}
+
+// walkOwners calls add for every ownerReference up to depth levels up, resolving the owners it
+// has to look past through the repo (from the ReplicaSet informer, for the common case).
+func (d *deepWatch) walkOwners(namespace string, owners []metav1.OwnerReference, depth int, add func(types.UID)) {
+	if depth == 0 {
+		return
+	}
+	for _, owner := range owners {
+		if _, ok := d.watched[owner.UID]; ok {
+			add(owner.UID)
+			continue
+		}
+		object, err := d.repo.ResolveOwner(namespace, owner)
+		if err != nil {
+			continue
+		}
+		d.walkOwners(namespace, object.Unstructured().GetOwnerReferences(), depth-1, add)
+	}
+}
+
+// flush re-renders every dirty object, in the order they were first seen.
+func (d *deepWatch) flush() {
+	for _, uid := range d.uids {
+		if !d.dirty[uid] {
+			continue
+		}
+		delete(d.dirty, uid)
+		if r, ok := d.watched[uid]; ok {
+			klog.V(5).InfoS("Re-rendering after a related change", "r", r)
+			d.render(r)
+		}
+	}
+}
+
+// runDeepWatch is `--watch --deep`. Unlike runWatch it keeps rendering in full: informers for the
+// kinds templates depend on most (input.InformerResources) answer those lookups locally, and
+// also report when one of them changes, so an object is re-rendered both when the query's own
+// watch reports it and when something related to it does -- a Deployment when one of its Pods
+// goes unready, say, although the Deployment object itself hasn't changed yet.
+func runDeepWatch(results *resource.Result, objects []RenderableObject, engine *renderEngine, repo *input.ResourceRepo, cfg *RenderConfig) error {
+	color.HiYellow("\nPrinted all existing resource statuses, starting to watch them and their related objects.\n\n")
+	cfg.Viper.Set("watching", true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan relatedChange, 256)
+	err := repo.StartInformers(ctx, cfg.Viper.GetString("namespace"), func(gvr schema.GroupVersionResource, obj input.Object) {
+		select {
+		case changes <- relatedChange{gvr: gvr, obj: obj}:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		klog.V(1).ErrorS(err, "Can't start informers")
+		return err
+	}
+	w, obj, err := startWatch(results)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+	d := newDeepWatch(objects, engine, repo)
+	ticker := time.NewTicker(deepWatchRerenderDelay)
+	defer ticker.Stop()
+	intr := interrupt.New(nil, cancel)
+	_ = intr.Run(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case e, ok := <-w.ResultChan():
+				if !ok {
+					klog.V(1).ErrorS(watchtools.ErrWatchClosed, "Watch failed", "obj", obj)
+					return watchtools.ErrWatchClosed
+				}
+				klog.V(5).InfoS("Processing watch event", "e", e)
+				switch e.Type {
+				case watch.Error:
+					err := apierrors.FromObject(e.Object)
+					klog.V(1).ErrorS(err, "Watch failed", "obj", obj)
+					return err
+				case watch.Bookmark:
+					continue
+				}
+				r, ok := decodeObj(e.Object, engine, repo)
+				if !ok {
+					continue
+				}
+				d.track(r, e.Type == watch.Deleted)
+				delete(d.dirty, r.GetUID())
+				d.render(r)
+			case change := <-changes:
+				for _, uid := range d.related(change) {
+					d.dirty[uid] = true
+				}
+			case <-ticker.C:
+				d.flush()
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/bergerx/kubectl-status/pkg/input"
+)
+
+func testWatchedObject(kind, namespace, name, uid string) RenderableObject {
+	metadata := map[string]interface{}{"name": name, "uid": uid}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return newTestRenderableObject(map[string]interface{}{"apiVersion": "v1", "kind": kind, "metadata": metadata})
+}
+
+// TestDeepWatchRelated verifies which watched objects a change to an informer-backed kind marks
+// for re-rendering under --watch --deep.
+func TestDeepWatchRelated(t *testing.T) {
+	d := newDeepWatch([]RenderableObject{
+		testWatchedObject("ReplicaSet", "web", "web-5d8f", "rs-uid"),
+		testWatchedObject("Service", "web", "web", "svc-uid"),
+		testWatchedObject("Node", "", "node-1", "node-uid"),
+		testWatchedObject("Pod", "web", "watched-pod", "pod-uid"),
+	}, nil, nil)
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	tests := []struct {
+		name   string
+		change relatedChange
+		want   []types.UID
+	}{
+		{
+			name: "a Pod marks its watched owner and its watched Node",
+			change: relatedChange{gvr: pods, obj: input.Object{
+				"metadata": map[string]interface{}{"name": "web-5d8f-a", "namespace": "web", "uid": "a", "ownerReferences": []interface{}{
+					map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web-5d8f", "uid": "rs-uid"},
+				}},
+				"spec": map[string]interface{}{"nodeName": "node-1"},
+			}},
+			want: []types.UID{"node-uid", "rs-uid"},
+		},
+		{
+			name: "an Event marks the object it is about",
+			change: relatedChange{gvr: schema.GroupVersionResource{Version: "v1", Resource: "events"}, obj: input.Object{
+				"metadata":       map[string]interface{}{"name": "e", "namespace": "web", "uid": "e"},
+				"involvedObject": map[string]interface{}{"kind": "Pod", "name": "watched-pod", "uid": "pod-uid"},
+			}},
+			want: []types.UID{"pod-uid"},
+		},
+		{
+			name: "an EndpointSlice marks its Service",
+			change: relatedChange{gvr: schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}, obj: input.Object{
+				"metadata": map[string]interface{}{"name": "web-abc", "namespace": "web", "uid": "s", "labels": map[string]interface{}{"kubernetes.io/service-name": "web"}},
+			}},
+			want: []types.UID{"svc-uid"},
+		},
+		{
+			name: "a change to a watched object is left to the query's own watch",
+			change: relatedChange{gvr: pods, obj: input.Object{
+				"metadata": map[string]interface{}{"name": "watched-pod", "namespace": "web", "uid": "pod-uid"},
+				"spec":     map[string]interface{}{"nodeName": "node-1"},
+			}},
+		},
+		{
+			name: "an unrelated Pod marks nothing",
+			change: relatedChange{gvr: pods, obj: input.Object{
+				"metadata": map[string]interface{}{"name": "other", "namespace": "other", "uid": "o"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[types.UID]bool{}
+			for _, uid := range d.related(tt.change) {
+				got[uid] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("related() = %v, want %v", got, tt.want)
+			}
+			for _, uid := range tt.want {
+				if !got[uid] {
+					t.Errorf("related() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestRenderWatchEventResetsCaches verifies the shallow --watch loop looks Services up afresh on
+// every event, so one created after the watch started isn't hidden by the first render's list.
+func TestRenderWatchEventResetsCaches(t *testing.T) {
+	te := newTestEngineWithResponses(t, "shop", map[string]string{
+		"/namespaces/shop/services": `{"kind":"ServiceList","apiVersion":"v1","items":[]}`,
+	})
+	servicesRequests := func() (n int) {
+		te.mu.Lock()
+		defer te.mu.Unlock()
+		for _, request := range te.requests {
+			if strings.HasPrefix(request, "/namespaces/shop/services?") {
+				n++
+			}
+		}
+		return n
+	}
+	_, err := te.repo.Services("shop")
+	require.NoError(t, err)
+	_, err = te.repo.Services("shop")
+	require.NoError(t, err)
+	require.Equal(t, 1, servicesRequests(), "the second lookup should be served from the cache")
+
+	renderWatchEvent(watch.Event{Type: watch.Modified, Object: testPodObject("web", "Running")}, te.engine, te.repo)
+	_, err = te.repo.Services("shop")
+	require.NoError(t, err)
+	assert.Equal(t, 2, servicesRequests())
+}